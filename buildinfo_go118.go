@@ -0,0 +1,29 @@
+//go:build go1.18
+// +build go1.18
+
+package cli
+
+import "runtime/debug"
+
+// readBuildSettings fill go version and vcs settings of build info.
+// vcs settings are used only when revision is not injected so that they are consistent.
+func readBuildSettings(info *VersionInfo, bi *debug.BuildInfo) {
+	if bi.GoVersion != "" {
+		info.GoVersion = bi.GoVersion
+	}
+	if info.Revision != "" {
+		return
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.modified":
+			info.Dirty = s.Value == "true"
+		case "vcs.time":
+			if info.Time == "" {
+				info.Time = s.Value
+			}
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !go1.18
+// +build !go1.18
+
+package cli
+
+import "runtime/debug"
+
+// readBuildSettings does nothing because build settings are not recorded before go1.18.
+func readBuildSettings(_ *VersionInfo, _ *debug.BuildInfo) {}
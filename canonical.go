@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ymgyt/cli/flags"
+	"github.com/ymgyt/cli/parser"
+)
+
+// CanonicalArgs return args which reproduce the parse result in canonical form.
+// command aliases are expanded to names, flags are written as "--long=value" right after the command
+// they are given to, bool flags are normalized to "--flag" or "--flag=false" and multi short flags are expanded.
+// args follow "--" if they could be parsed as flags or sub commands. deprecated flags are written as
+// the flags which replace them. secret flag values are written as is, use RedactedArgs for logging.
+func (c *Command) CanonicalArgs(pr *parser.Result) ([]string, error) {
+	return c.canonicalArgs(pr, false)
+}
+
+// RedactedArgs is CanonicalArgs whose secret flag values are replaced with flags.Redacted.
+// it is intended to log invocations and does not reproduce the parse result.
+func (c *Command) RedactedArgs(pr *parser.Result) ([]string, error) {
+	return c.canonicalArgs(pr, true)
+}
+
+func (c *Command) canonicalArgs(pr *parser.Result, redact bool) ([]string, error) {
+	c.lasyInit()
+	cmds, owner := c.resolve(pr)
+	var canonical []string
+	path := c.Name
+	for i, cmd := range cmds {
+		if i > 0 {
+			canonical = append(canonical, cmd.Name)
+			path += " " + pr.Commands()[i-1]
+		}
+		for _, pf := range pr.FlagsByPath(path) {
+			arg, err := canonicalFlag(owner(pf), pf, pr.Input(), redact)
+			if err != nil {
+				return nil, err
+			}
+			canonical = append(canonical, arg)
+		}
+	}
+
+	args := pr.Args()
+	if cmds[len(cmds)-1].needsTermination(args, c.ResponseFiles) {
+		canonical = append(canonical, "--")
+	}
+	return append(canonical, args...), nil
+}
+
+func canonicalFlag(cmd *Command, pf *parser.Flag, input []string, redact bool) (string, error) {
+	f, err := cmd.lookupFlag(pf.Name)
+	if err != nil {
+		return "", flagError(parser.KindUnknownFlag, pf, input, "unknown flag")
+	}
+	if replacedBy := f.ReplacedBy; replacedBy != "" {
+		if f, err = cmd.lookupFlag(replacedBy); err != nil {
+			return "", flagError(parser.KindUnknownFlag, pf, input, fmt.Sprintf("flag %s not found", flagDisplayName(replacedBy)))
+		}
+	}
+	name := flagDisplayName(f.Name())
+	if !pf.IsBool {
+		if redact && f.Secret {
+			return name + "=" + flags.Redacted, nil
+		}
+		return name + "=" + pf.Value, nil
+	}
+	if pf.BoolValue {
+		return name, nil
+	}
+	return name + "=false", nil
+}
+
+// needsTermination reports whether args could be taken as flags, sub commands or response files.
+func (c *Command) needsTermination(args []string, responseFiles bool) bool {
+	for _, arg := range args {
+		switch {
+		case len(arg) > 1 && strings.HasPrefix(arg, "-"):
+			return true
+		case responseFiles && strings.HasPrefix(arg, "@"):
+			return true
+		case c.Lookup(arg) != nil:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+package cli_test
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+	"testing/quick"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli"
+	"github.com/ymgyt/cli/flags"
+	"github.com/ymgyt/cli/parser"
+)
+
+func TestCommand_CanonicalArgs(t *testing.T) {
+	tests := map[string]struct {
+		args []string
+		want []string
+	}{
+		"alias": {
+			args: []string{"get", "rs", "a"},
+			want: []string{"get", "replicaset", "a"},
+		},
+		"flags before sub command": {
+			args: []string{"-l", "2", "get", "pod"},
+			want: []string{"--level=2", "get", "pod"},
+		},
+		"cluster and values": {
+			args: []string{"get", "pod", "-vh", "-l", "3", "--nums", "1,2", "--help=false", "x"},
+			want: []string{"get", "pod", "-v", "--help", "--level=3", "--nums=1,2", "--help=false", "x"},
+		},
+		"termination": {
+			args: []string{"get", "pod", "--", "-x", "a"},
+			want: []string{"get", "pod", "--", "-x", "a"},
+		},
+		"sub command name as arg": {
+			args: []string{"get", "--", "pod"},
+			want: []string{"get", "--", "pod"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := buildCmd(nil, nil, nil)
+			pr, err := cmd.Parse(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := cmd.CanonicalArgs(pr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("(-got +want)%s", diff)
+			}
+		})
+	}
+
+	t.Run("deprecated and secret", func(t *testing.T) {
+		var level int
+		var token string
+		cmd := &cli.Command{Name: "app"}
+		cmd.Options().
+			Add(&cli.IntOpt{Var: &level, Long: "level"}).
+			Add(&cli.DeprecatedOpt{Long: "lvl", ReplacedBy: "level"}).
+			Add(&cli.StringOpt{Var: &token, Long: "token", Secret: true})
+		pr, err := cmd.Parse([]string{"--lvl", "3", "--token", "hunter2", "a"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := cmd.CanonicalArgs(pr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, []string{"--level=3", "--token=hunter2", "a"}); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+		got, err = cmd.RedactedArgs(pr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, []string{"--level=3", "--token=" + flags.Redacted, "a"}); diff != "" {
+			t.Errorf("redacted (-got +want)%s", diff)
+		}
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		cmd := buildCmd(nil, nil, nil)
+		pr, err := cmd.Parse([]string{"get", "pod", "--unknown=1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cmd.CanonicalArgs(pr); err == nil {
+			t.Error("want error")
+		}
+	})
+}
+
+// invocation is a random valid command line of buildCmd.
+type invocation []string
+
+func (invocation) Generate(r *rand.Rand, size int) reflect.Value {
+	pick := func(ss ...string) string { return ss[r.Intn(len(ss))] }
+	flagArgs := func() []string {
+		var fs []string
+		for i := r.Intn(3); i > 0; i-- {
+			n := strconv.Itoa(r.Intn(100))
+			switch r.Intn(6) {
+			case 0:
+				fs = append(fs, pick("-v", "-h", "--help", "-vh", "-hv"))
+			case 1:
+				fs = append(fs, pick("-v", "-h", "--help")+"="+pick("true", "false", "1", "0"))
+			case 2:
+				fs = append(fs, pick("-l", "--level"), n)
+			case 3:
+				fs = append(fs, pick("-l=", "--level=")+n)
+			case 4:
+				fs = append(fs, "--nums", n+","+n)
+			default:
+				fs = append(fs, "--nums="+n)
+			}
+		}
+		return fs
+	}
+
+	var inv invocation
+	switch r.Intn(4) {
+	case 0:
+		inv = append(inv, "version")
+	case 1:
+		inv = append(inv, "get", pick("rs", "replicaset"))
+	default:
+		inv = append(inv, flagArgs()...)
+		inv = append(inv, "get")
+		inv = append(inv, flagArgs()...)
+		inv = append(inv, "pod")
+		inv = append(inv, flagArgs()...)
+	}
+	words := []string{"a", "b c", "pod", "-", "x=y"}
+	for i := r.Intn(size + 1); i > 0; i-- {
+		inv = append(inv, pick(words...))
+	}
+	if r.Intn(2) == 0 {
+		inv = append(inv, "--", pick("-x", "--level", "get", "--"))
+	}
+	return reflect.ValueOf(inv)
+}
+
+func TestCommand_CanonicalArgs_roundTrip(t *testing.T) {
+	cmd := buildCmd(nil, nil, nil)
+	canonical := func(args []string) ([]string, *parser.Result) {
+		t.Helper()
+		pr, err := cmd.Parse(args)
+		if err != nil {
+			t.Fatalf("Parse(%q) %v", args, err)
+		}
+		c, err := cmd.CanonicalArgs(pr)
+		if err != nil {
+			t.Fatalf("CanonicalArgs(%q) %v", args, err)
+		}
+		return c, pr
+	}
+	find := func(c *cli.Command, name string) *flags.Flag {
+		for _, f := range c.Flags() {
+			if f.HasName(name) {
+				return f
+			}
+		}
+		for ; c != nil; c = c.Parent() {
+			for _, f := range c.PersistentFlags() {
+				if f.HasName(name) {
+					return f
+				}
+			}
+		}
+		return nil
+	}
+	// resolved describe the command to run by its path,
+	// then each flag by the path of the command which receives it, flag name and value.
+	resolved := func(pr *parser.Result) []string {
+		runCmd, path := cmd, cmd.Name
+		cmds := map[string]*cli.Command{path: cmd}
+		for _, name := range pr.Commands() {
+			runCmd = runCmd.Lookup(name)
+			path += " " + name
+			cmds[path] = runCmd
+		}
+		got := []string{runCmd.Path()}
+		for _, pf := range pr.AllFlags() {
+			owner, f := runCmd, (*flags.Flag)(nil)
+			if c, ok := cmds[pf.Path]; ok {
+				if f = find(c, pf.Name); f != nil {
+					owner = c
+				}
+			}
+			if f == nil {
+				f = find(runCmd, pf.Name)
+			}
+			if f == nil {
+				got = append(got, "unknown "+pf.Name)
+				continue
+			}
+			value := pf.Value
+			if pf.IsBool {
+				value = strconv.FormatBool(pf.BoolValue)
+			}
+			got = append(got, owner.Path()+" "+f.Name()+"="+value)
+		}
+		return got
+	}
+
+	property := func(inv invocation) bool {
+		c1, r1 := canonical(inv)
+		c2, r2 := canonical(c1)
+		if diff := cmp.Diff(c1, c2); diff != "" {
+			t.Logf("%q: canonical form is not stable (-first +second)%s", inv, diff)
+			return false
+		}
+		if diff := cmp.Diff(r1.Args(), r2.Args()); diff != "" {
+			t.Logf("%q: args (-got +want)%s", inv, diff)
+			return false
+		}
+		if diff := cmp.Diff(resolved(r1), resolved(r2)); diff != "" {
+			t.Logf("%q: command and flags (-got +want)%s", inv, diff)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
@@ -5,10 +5,16 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/ymgyt/cli/flags"
+	"github.com/ymgyt/cli/internal/term"
 	"github.com/ymgyt/cli/parser"
+	"github.com/ymgyt/cli/prompt"
 )
 
 type Command struct {
@@ -19,14 +25,72 @@ type Command struct {
 	Help        func(io.Writer, *Command)
 	Run         func(context.Context, *Command, []string)
 	SubCommands []*Command
+	Examples    []*Example
+	// HelpTemplate overrides HelpTemplate for this command and its sub commands.
+	HelpTemplate string
+	// GroupID is an ID of parent's Groups in which command is displayed in help.
+	GroupID string
+	// Groups defines titles and order of sub command groups in help.
+	Groups []*Group
+	// DisableSort keeps sub commands and flags in the order they are added in help.
+	DisableSort bool
+	// Hidden command is not displayed in help and completions.
+	Hidden bool
+	// Deprecated is a message displayed when command is used.
+	Deprecated string
+	// ResponseFiles enables "@path" arguments expansion. only root command's one is respected.
+	ResponseFiles bool
+	// ArgsMode decides how flags after positional arguments are handled. default is parser.Interspersed.
+	// use parser.StopAtFirstArg or parser.PassThroughUnknownFlags for commands which wrap other programs.
+	ArgsMode parser.ArgsMode
+	// UserAliases maps user defined alias names to command lines like "deploy --env=prod".
+	// the first argument is expanded before parsing if it is an alias. they never shadow sub commands and their aliases.
+	UserAliases map[string]string
+	// EnablePlugins executes "<command path>-<name>" found in PluginDirs or $PATH when the first argument is not a sub command.
+	// parsing flags stops at the first argument as parser.StopAtFirstArg so that they are passed to the plugin.
+	EnablePlugins bool
+	// PluginDirs are searched before $PATH.
+	PluginDirs []string
+	// Exit is called with non zero exit code of plugin or after signal. default is os.Exit.
+	Exit func(int)
+	// HandleSignals derives Run context from SIGINT and SIGTERM. first signal cancels the context and
+	// second one forces exit. exit code is 128 + signal number like 130 for SIGINT. only executed command's one is used.
+	HandleSignals bool
+	// GracePeriod is time given to Run and PostRun after first signal. default is 5 seconds.
+	GracePeriod time.Duration
+	// PostRun is called after Run of the command or its sub commands, from the command to root.
+	// after signal, it is called with a new context which expires when GracePeriod from the signal is over.
+	PostRun func(context.Context, *Command)
+	// Version is printed by "--version" flag and "version" sub command. if it is empty,
+	// BuildVersion or module version of build info is used. only root command's one is respected.
+	Version string
+	// VersionFlag adds persistent "--version" flag which prints version instead of running the command or its sub commands.
+	VersionFlag bool
+	// VersionCommand adds "version" sub command which supports "-o json".
+	// it is not added if SubCommands already has "version" command, and replaced by AddCommand of "version" command.
+	// VersionFlag and VersionCommand take effect when the command is initialized by the first call of its methods
+	// like AddCommand, Lookup or Execute. changing them after that is ignored.
+	VersionCommand bool
 
 	Stdin  io.Reader
 	Stdout io.Writer
 	Stderr io.Writer
 
-	flagSet  *flags.FlagSet
-	parent   *Command
-	onceInit sync.Once
+	flagSet           *flags.FlagSet
+	persistentFlagSet *flags.FlagSet
+	parent            *Command
+	runDefaulted      bool
+	subIndex          *subCommandIndex
+	optionErrs        []*OptionError
+	showVersion       bool
+	builtin           bool
+	onceInit          sync.Once
+}
+
+// Group is a sub command group displayed in help.
+type Group struct {
+	ID    string
+	Title string
 }
 
 func (c *Command) Execute(ctx context.Context) {
@@ -44,51 +108,234 @@ func (c *Command) ExecuteWithArgs(ctx context.Context, args []string) {
 }
 
 func (c *Command) ExecuteWithParseResult(ctx context.Context, pr *parser.Result) {
-	var runCmd = c
-	for _, sub := range pr.Commands() {
-		if runCmd == nil {
-			panic("runCmd == nil, something went wrong")
+	c.lasyInit()
+	cmds, owner := c.resolve(pr)
+	for _, cmd := range cmds[1:] {
+		if cmd.Deprecated != "" {
+			fmt.Fprintf(c.Stderr, "warning: command %s is deprecated, %s\n", cmd.Name, cmd.Deprecated)
 		}
-		runCmd = runCmd.Lookup(sub)
 	}
-	if err := runCmd.ConsumeFlags(pr.AllFlags()); err != nil {
+	runCmd := cmds[len(cmds)-1]
+	if err := runCmd.consumeFlags(pr.AllFlags(), pr.Input(), owner); err != nil {
 		c.handleParseErr(err)
 		return
 	}
-	runCmd.Run(ctx, runCmd, pr.Args())
+	for _, cmd := range cmds {
+		if cmd.showVersion {
+			cmd.writeVersion(cmd.Stdout, "text") // nolint: errcheck
+			return
+		}
+	}
+	if args := pr.Args(); len(args) > 0 {
+		if p, ok := runCmd.LookupPlugin(args[0]); ok {
+			// plugin may prompt by itself, so flags are resolved without prompt.
+			if err := runCmd.resolveUnsetFlags(ctx, false); err != nil {
+				c.handleParseErr(err)
+				return
+			}
+			if code := runCmd.runPlugin(ctx, p, args[1:]); code != 0 {
+				c.Exit(code)
+			}
+			return
+		}
+	}
+	if err := runCmd.resolveUnsetFlags(ctx, true); err != nil {
+		c.handleParseErr(err)
+		return
+	}
+	runCtx, h := c.notifySignals(ctx)
+	defer h.stop()
+	runCmd.Run(runCtx, runCmd, pr.Args())
+
+	postCtx, cancel := h.postRunContext(runCtx)
+	defer cancel()
+	for cmd := runCmd; cmd != nil; cmd = cmd.parent {
+		if cmd.PostRun != nil {
+			cmd.PostRun(postCtx, runCmd)
+		}
+	}
+	h.exit()
+}
+
+// resolve return c and the commands of parse result, and a function which return the command owning the flag.
+func (c *Command) resolve(pr *parser.Result) ([]*Command, func(*parser.Flag) *Command) {
+	cmds := []*Command{c}
+	path := c.Name
+	owners := map[string]*Command{path: c}
+	for _, sub := range pr.Commands() {
+		cmd := cmds[len(cmds)-1].Lookup(sub)
+		if cmd == nil {
+			panic("runCmd == nil, something went wrong")
+		}
+		cmd.lasyInit()
+		cmds = append(cmds, cmd)
+		path += " " + sub
+		owners[path] = cmd
+	}
+	runCmd := cmds[len(cmds)-1]
+	// flag is applied to the command which it is given to. if the command does not know it,
+	// it is applied to runCmd because parser accepts flags of sub commands before them.
+	owner := func(pf *parser.Flag) *Command {
+		if cmd, ok := owners[pf.Path]; ok {
+			if _, err := cmd.lookupFlag(pf.Name); err == nil {
+				return cmd
+			}
+		}
+		return runCmd
+	}
+	return cmds, owner
 }
 
 // AddCommand add subcommand. if same name sub command already added, it panic.
+// built-in sub commands like "version" are replaced instead.
 func (c *Command) AddCommand(sub *Command) *Command {
 	c.lasyInit()
-	if sub := c.Lookup(sub.Name); sub != nil {
-		panic(fmt.Sprintf("%s already exists", sub.Name))
+	if existing := c.Lookup(sub.Name); existing != nil {
+		if !existing.builtin {
+			panic(fmt.Sprintf("%s already exists", sub.Name))
+		}
+		sub.parent = c
+		for i := range c.SubCommands {
+			if c.SubCommands[i] == existing {
+				c.SubCommands[i] = sub
+			}
+		}
+		c.subIndex.invalidate()
+		return c
 	}
 	sub.parent = c
-	c.SubCommands = append(c.SubCommands, sub)
+	old := c.SubCommands
+	c.SubCommands = append(old, sub)
+	c.subIndex.add(old, c.SubCommands, sub)
 	return c
 }
 
+// Flags return own flags, including hidden ones.
+func (c *Command) Flags() []*flags.Flag {
+	c.lasyInit()
+	var fs []*flags.Flag
+	c.flagSet.Traverse(func(f *flags.Flag) { fs = append(fs, f) })
+	return fs
+}
+
+// PersistentFlags return flags inherited by sub commands, including hidden ones.
+func (c *Command) PersistentFlags() []*flags.Flag {
+	c.lasyInit()
+	var fs []*flags.Flag
+	c.persistentFlagSet.Traverse(func(f *flags.Flag) { fs = append(fs, f) })
+	return fs
+}
+
+// Reset restores flags of the command and its sub commands to their defaults
+// so that the command can be executed again.
+func (c *Command) Reset() {
+	c.walk(func(cmd *Command) {
+		cmd.lasyInit()
+		cmd.flagSet.Reset()
+		cmd.persistentFlagSet.Reset()
+	})
+}
+
+// Parent return parent command. root command returns nil.
+func (c *Command) Parent() *Command { return c.parent }
+
+// Path return command names from root joined by space.
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.Path() + " " + c.Name
+}
+
+// Lookup return sub command of name or alias. nil is returned if not found.
 func (c *Command) Lookup(name string) *Command {
-	for _, sub := range c.SubCommands {
-		if sub.Name == name {
-			return sub
-		}
-		for _, alias := range sub.Aliases {
-			if alias == name {
-				return sub
+	c.lasyInit()
+	return c.subIndex.lookup(c.SubCommands, name)
+}
+
+// Invalidate drops the index of sub commands used by Lookup.
+// it must be called if sub commands are replaced in SubCommands, or their names or aliases are changed after they are looked up.
+// AddCommand and assigning or appending SubCommands do not require it.
+func (c *Command) Invalidate() {
+	c.lasyInit()
+	c.subIndex.invalidate()
+}
+
+// subCommandIndex maps names and aliases to sub commands.
+// it holds *subCommandSnapshot which is rebuilt when SubCommands slice differs or it is invalidated.
+type subCommandIndex struct {
+	snapshot atomic.Value
+}
+
+type subCommandSnapshot struct {
+	subs     []*Command
+	commands map[string]*Command
+}
+
+func (idx *subCommandIndex) lookup(subs []*Command, name string) *Command {
+	snapshot, _ := idx.snapshot.Load().(*subCommandSnapshot)
+	if snapshot == nil || !sameCommands(snapshot.subs, subs) {
+		snapshot = &subCommandSnapshot{subs: subs, commands: make(map[string]*Command, len(subs))}
+		// iterate in reverse so that former sub command wins like linear search.
+		for i := len(subs) - 1; i >= 0; i-- {
+			for _, alias := range subs[i].Aliases {
+				snapshot.commands[alias] = subs[i]
 			}
+			snapshot.commands[subs[i].Name] = subs[i]
 		}
+		idx.snapshot.Store(snapshot)
 	}
-	return nil
+	return snapshot.commands[name]
+}
+
+// add update the index for sub appended to old so that adding many sub commands does not rebuild it each time.
+// like AddCommand, it must not be called concurrently with lookup.
+func (idx *subCommandIndex) add(old, subs []*Command, sub *Command) {
+	snapshot, _ := idx.snapshot.Load().(*subCommandSnapshot)
+	if snapshot == nil || !sameCommands(snapshot.subs, old) {
+		return
+	}
+	for _, name := range append([]string{sub.Name}, sub.Aliases...) {
+		if _, ok := snapshot.commands[name]; !ok {
+			snapshot.commands[name] = sub
+		}
+	}
+	snapshot.subs = subs
+}
+
+func (idx *subCommandIndex) invalidate() {
+	idx.snapshot.Store((*subCommandSnapshot)(nil))
+}
+
+// sameCommands reports whether a and b are the same slice. elements are not compared.
+func sameCommands(a, b []*Command) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
 }
 
+// ConsumeFlags set parsed flags. if deprecated flag is used, warning is printed once.
 func (c *Command) ConsumeFlags(pfs []*parser.Flag) error {
-	flagSet := c.flagSet
+	return c.consumeFlags(pfs, nil, func(*parser.Flag) *Command { return c })
+}
+
+// consumeFlags set each parsed flag to the flag of the command returned by owner.
+// input is used to render errors.
+func (c *Command) consumeFlags(pfs []*parser.Flag, input []string, owner func(*parser.Flag) *Command) error {
+	c.lasyInit()
+	warned := make(map[string]bool)
 	for _, pf := range pfs {
-		f, err := flagSet.Lookup(pf.Name)
+		cmd := owner(pf)
+		f, err := cmd.lookupFlag(pf.Name)
 		if err != nil {
-			return &ParseError{FlagName: pf.Name, Message: fmt.Sprintf("flag %s not found", pf.Name)}
+			return flagError(parser.KindUnknownFlag, pf, input, "unknown flag")
+		}
+		if msg, deprecated := f.DeprecationMessage(pf.Name); deprecated && !warned[pf.Name] {
+			warned[pf.Name] = true
+			fmt.Fprintf(c.Stderr, "warning: flag %s is deprecated, %s\n", flagDisplayName(pf.Name), msg)
+		}
+		if replacedBy := f.ReplacedBy; replacedBy != "" {
+			if f, err = cmd.lookupFlag(replacedBy); err != nil {
+				return &ParseError{FlagName: pf.Name, Message: fmt.Sprintf("flag %s not found", replacedBy)}
+			}
 		}
 		value := pf.Value
 		if pf.IsBool {
@@ -98,17 +345,93 @@ func (c *Command) ConsumeFlags(pfs []*parser.Flag) error {
 			}
 		}
 		if err := f.Set(value); err != nil {
-			return &ParseError{FlagName: pf.Name, Message: err.Error()}
+			return flagError(parser.KindInvalidValue, pf, input, err.Error())
 		}
 	}
 	return nil
 }
 
+// resolveUnsetFlags fill flags not given by command line from environment variables,
+// then from interactive prompt if interactive is true and stdin is a terminal.
+func (c *Command) resolveUnsetFlags(ctx context.Context, interactive bool) error {
+	var err error
+	c.traverseFlags(func(f *flags.Flag) {
+		if err != nil || f.IsSet {
+			return
+		}
+		if v, ok := os.LookupEnv(f.EnvVar); ok && f.EnvVar != "" {
+			if setErr := f.Set(v); setErr != nil {
+				err = &ParseError{FlagName: f.Name(), Message: fmt.Sprintf("env %s: %s", f.EnvVar, setErr)}
+			}
+			return
+		}
+		if !interactive || f.Prompt == "" || !term.IsTerminal(c.Stdin) {
+			return
+		}
+		session := prompt.New().Context(ctx).SetReader(c.Stdin).SetWriter(c.Stderr).Display(f.Prompt)
+		if f.Secret {
+			session = session.NoEcho()
+		}
+		v, promptErr := session.Input()
+		if promptErr == prompt.ErrInterrupted {
+			// terminal is already restored by prompt, exit like signal handler does.
+			c.Exit(128 + int(syscall.SIGINT))
+		}
+		if promptErr != nil {
+			err = &ParseError{FlagName: f.Name(), Message: promptErr.Error()}
+			return
+		}
+		if setErr := f.Set(v); setErr != nil {
+			err = &ParseError{FlagName: f.Name(), Message: setErr.Error()}
+		}
+	})
+	return err
+}
+
+// lookupFlag lookup flag from own flags, then persistent flags of self and ancestors.
+func (c *Command) lookupFlag(name string) (*flags.Flag, error) {
+	c.lasyInit()
+	if f, err := c.flagSet.Lookup(name); err == nil {
+		return f, nil
+	}
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if cmd.persistentFlagSet == nil {
+			continue
+		}
+		if f, err := cmd.persistentFlagSet.Lookup(name); err == nil {
+			return f, nil
+		}
+	}
+	return nil, flags.ErrFlagNotFound
+}
+
+// traverseFlags call fn with own flags, own persistent flags and inherited flags.
+func (c *Command) traverseFlags(fn func(*flags.Flag)) {
+	c.lasyInit()
+	c.flagSet.Traverse(fn)
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if cmd.persistentFlagSet != nil {
+			cmd.persistentFlagSet.Traverse(fn)
+		}
+	}
+}
+
+func flagDisplayName(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
 func (c *Command) lasyInit() {
 	c.onceInit.Do(func() {
 		if c.flagSet == nil {
 			c.flagSet = &flags.FlagSet{}
 		}
+		c.subIndex = &subCommandIndex{}
+		if c.persistentFlagSet == nil {
+			c.persistentFlagSet = &flags.FlagSet{}
+		}
 		if c.Stdin == nil {
 			c.Stdin = os.Stdin
 		}
@@ -121,11 +444,16 @@ func (c *Command) lasyInit() {
 		if c.Help == nil {
 			c.Help = c.DefaultHelp()
 		}
+		if c.Exit == nil {
+			c.Exit = os.Exit
+		}
 		if c.Run == nil {
+			c.runDefaulted = true
 			c.Run = func(_ context.Context, _ *Command, _ []string) {
 				c.Help(c.Stderr, c)
 			}
 		}
+		c.addVersion()
 	})
 }
 
@@ -134,7 +462,14 @@ func (c *Command) DefaultHelp() func(io.Writer, *Command) {
 }
 
 func (c *Command) Parse(args []string) (*parser.Result, error) {
-	return parser.New(&commander{c}).Parse(args)
+	c.lasyInit()
+	args, err := c.expandUserAliases(args)
+	if err != nil {
+		return nil, err
+	}
+	p := parser.New(&commander{c: c, index: make(boolFlagIndex)})
+	p.ResponseFiles = c.ResponseFiles
+	return p.Parse(args)
 }
 
 type ParseError struct {
@@ -146,32 +481,116 @@ func (e *ParseError) Error() string { return e.Message }
 
 func (c *Command) handleParseErr(err error) {
 	fmt.Fprintf(c.Stderr, "parse error: %s\n", err)
+	if pe, ok := err.(*parser.Error); ok {
+		redacted := *pe
+		redacted.Args = c.redactArgs(pe.Args)
+		if diag := redacted.Render(); diag != "" {
+			fmt.Fprint(c.Stderr, indent(2, diag))
+		}
+	}
+}
+
+// redactArgs return copy of args whose secret flag values are replaced with flags.Redacted.
+func (c *Command) redactArgs(args []string) []string {
+	secret := func(name string) bool {
+		found := false
+		c.walk(func(cmd *Command) {
+			cmd.lasyInit()
+			if f, err := cmd.lookupFlag(name); err == nil && f.Secret {
+				found = true
+			}
+		})
+		return found
+	}
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 0; i < len(redacted); i++ {
+		arg := redacted[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		nameValue := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)
+		if !secret(nameValue[0]) {
+			continue
+		}
+		if len(nameValue) == 2 {
+			redacted[i] = arg[:len(arg)-len(nameValue[1])] + flags.Redacted
+		} else if i+1 < len(redacted) {
+			i++
+			redacted[i] = flags.Redacted
+		}
+	}
+	return redacted
+}
+
+func flagError(kind parser.ErrorKind, pf *parser.Flag, input []string, msg string) *parser.Error {
+	return &parser.Error{
+		Flag:  flagDisplayName(pf.Name),
+		Msg:   msg,
+		Kind:  kind,
+		Index: pf.Index,
+		Path:  pf.Path,
+		Args:  input,
+	}
 }
 
 type commander struct {
-	c *Command
+	c     *Command
+	index boolFlagIndex
 }
 
 func (c *commander) Name() string { return c.c.Name }
 func (c *commander) LookupSubCommand(name string) (parser.Commander, bool) {
-	c.c.lasyInit()
 	sub := c.c.Lookup(name)
 	if sub == nil {
 		return nil, false
 	}
-	return &commander{c: sub}, true
+	return &commander{c: sub, index: c.index}, true
+}
+
+func (c *commander) HasFlag(name string) bool {
+	_, err := c.c.lookupFlag(name)
+	return err == nil
+}
+
+func (c *commander) ArgsMode() parser.ArgsMode {
+	if c.c.EnablePlugins && c.c.ArgsMode == parser.Interspersed {
+		return parser.StopAtFirstArg
+	}
+	return c.c.ArgsMode
 }
+
+// IsBoolFlag looks up own flags first, then flags of sub commands so that
+// flags of sub commands can be given before them.
 func (c *commander) IsBoolFlag(name string) bool {
-	c.c.lasyInit()
-	// まず自分のflagsetをみにいく
-	f, err := c.c.flagSet.Lookup(name)
-	if err == nil {
-		return f.IsBool()
+	return c.index.of(c.c)[name]
+}
+
+// boolFlagIndex caches whether a flag name is bool per command during a parse.
+type boolFlagIndex map[*Command]map[string]bool
+
+func (idx boolFlagIndex) of(c *Command) map[string]bool {
+	if m, ok := idx[c]; ok {
+		return m
 	}
-	for _, sub := range c.c.SubCommands {
-		if (&commander{c: sub}).IsBoolFlag(name) {
-			return true
+	m := make(map[string]bool)
+	c.traverseFlags(func(f *flags.Flag) {
+		for _, name := range flagNamesOf(f) {
+			if _, found := m[name]; !found {
+				m[name] = f.IsBool()
+			}
+		}
+	})
+	for _, sub := range c.SubCommands {
+		for name, isBool := range idx.of(sub) {
+			if _, found := m[name]; !found && isBool {
+				m[name] = true
+			}
 		}
 	}
-	return false
+	idx[c] = m
+	return m
 }
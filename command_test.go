@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -84,6 +85,166 @@ func TestCommand_Execute(t *testing.T) {
 	})
 }
 
+func TestCommand_Execute_deprecated(t *testing.T) {
+	build := func(stderr io.Writer, max *int, verbose *bool) *cli.Command {
+		root := &cli.Command{Name: "root", Stderr: stderr}
+		old := &cli.Command{
+			Name:       "old",
+			Deprecated: "use new instead",
+			Stderr:     stderr,
+			Run:        func(_ context.Context, _ *cli.Command, _ []string) {},
+		}
+		err := old.Options().
+			Add(&cli.IntOpt{Var: max, Long: "max", DeprecatedAliases: map[string]string{"limit": "use --max instead"}}).
+			Add(&cli.BoolOpt{Var: verbose, Long: "verbose"}).
+			Add(&cli.DeprecatedOpt{Long: "debug", ReplacedBy: "verbose"}).Err
+		if err != nil {
+			t.Fatal(err)
+		}
+		return root.AddCommand(old)
+	}
+
+	var stderr bytes.Buffer
+	var max int
+	var verbose bool
+	build(&stderr, &max, &verbose).ExecuteWithArgs(context.Background(), []string{"old", "--limit=10", "--debug"})
+
+	if max != 10 {
+		t.Errorf("deprecated alias should work. got %d", max)
+	}
+	if !verbose {
+		t.Error("deprecated flag should set replacement flag")
+	}
+	want := `warning: command old is deprecated, use new instead
+warning: flag --limit is deprecated, use --max instead
+warning: flag --debug is deprecated, use --verbose instead
+`
+	if diff := cmp.Diff(stderr.String(), want); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+
+	t.Run("replaced flag set multiple times", func(t *testing.T) {
+		var stderr bytes.Buffer
+		build(&stderr, &max, &verbose).ExecuteWithArgs(context.Background(), []string{"old", "--verbose", "--debug"})
+		if !strings.Contains(stderr.String(), "parse error") {
+			t.Errorf("want parse error, got %q", stderr.String())
+		}
+	})
+
+	t.Run("replacement not added", func(t *testing.T) {
+		err := (&cli.Command{}).Options().Add(&cli.DeprecatedOpt{Long: "debug", ReplacedBy: "verbose"}).Err
+		if err == nil {
+			t.Error("want error, but no error")
+		}
+	})
+}
+
+func TestCommand_PersistentOptions(t *testing.T) {
+	var verbose bool
+	var label string
+	var got []string
+	build := func() *cli.Command {
+		root := &cli.Command{Name: "root"}
+		root.PersistentOptions().Add(&cli.BoolOpt{Var: &verbose, Long: "verbose", Short: "v"})
+		sub := &cli.Command{Name: "sub", Run: func(_ context.Context, _ *cli.Command, args []string) { got = args }}
+		sub.Options().Add(&cli.StringOpt{Var: &label, Long: "label"})
+		return root.AddCommand(sub)
+	}
+
+	tests := map[string][]string{
+		"before sub command": {"-v", "sub", "--label=app", "arg"},
+		"after sub command":  {"sub", "--label=app", "--verbose", "arg"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			got = nil
+			build().ExecuteWithArgs(context.Background(), args)
+			if !verbose || label != "app" {
+				t.Errorf("got verbose=%v, label=%q", verbose, label)
+			}
+			if diff := cmp.Diff(got, []string{"arg"}); diff != "" {
+				t.Errorf("(-got +want)%s", diff)
+			}
+		})
+	}
+}
+
+func TestCommand_Execute_flagOwner(t *testing.T) {
+	var parentOuts, leafOuts []string
+	var ran bool
+	root := &cli.Command{Name: "root"}
+	parent := &cli.Command{Name: "sub"}
+	parent.Options().Add(&cli.StringsOpt{Var: &parentOuts, Long: "outs"})
+	leaf := &cli.Command{Name: "sub", Run: func(_ context.Context, _ *cli.Command, _ []string) { ran = true }}
+	leaf.Options().Add(&cli.StringsOpt{Var: &leafOuts, Long: "outs"})
+	root.AddCommand(parent.AddCommand(leaf))
+
+	root.ExecuteWithArgs(context.Background(), []string{"sub", "--outs", "a", "sub", "--outs=3", "--outs", "1", "--outs=2"})
+	if !ran {
+		t.Fatal("leaf command not executed")
+	}
+	if diff := cmp.Diff(parentOuts, []string{"a"}); diff != "" {
+		t.Errorf("parent (-got +want)%s", diff)
+	}
+	if diff := cmp.Diff(leafOuts, []string{"3", "1", "2"}); diff != "" {
+		t.Errorf("leaf (-got +want)%s", diff)
+	}
+}
+
+func TestCommand_Execute_argsMode(t *testing.T) {
+	var verbose bool
+	var got []string
+	root := &cli.Command{Name: "app"}
+	root.PersistentOptions().Add(&cli.BoolOpt{Var: &verbose, Long: "verbose", Short: "v"})
+	exec := &cli.Command{
+		Name:     "exec",
+		ArgsMode: parser.StopAtFirstArg,
+		Run:      func(_ context.Context, _ *cli.Command, args []string) { got = args },
+	}
+	root.AddCommand(exec)
+
+	root.ExecuteWithArgs(context.Background(), []string{"exec", "-v", "mycmd", "--flag", "-v"})
+	if !verbose {
+		t.Error("flag before first arg should be parsed")
+	}
+	if diff := cmp.Diff(got, []string{"mycmd", "--flag", "-v"}); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+}
+
+func TestCommand_Reset(t *testing.T) {
+	var level int
+	var outs []string
+	var verbose bool
+	var got []string
+	root := &cli.Command{Name: "root"}
+	root.PersistentOptions().Add(&cli.BoolOpt{Var: &verbose, Long: "verbose"})
+	sub := &cli.Command{Name: "sub", Run: func(_ context.Context, _ *cli.Command, _ []string) {
+		got = append(got, fmt.Sprintf("level=%d outs=%v verbose=%v", level, outs, verbose))
+	}}
+	sub.Options().
+		Add(&cli.IntOpt{Var: &level, Long: "level", Default: 1}).
+		Add(&cli.StringsOpt{Var: &outs, Long: "outs", Default: []string{"a"}})
+	root.AddCommand(sub)
+
+	for _, args := range [][]string{
+		{"sub", "--level=2", "--outs=b", "--outs=c", "--verbose"},
+		{"sub"},
+		{"sub", "--level=3", "--outs=d"},
+	} {
+		root.Reset()
+		root.ExecuteWithArgs(context.Background(), args)
+	}
+	want := []string{
+		"level=2 outs=[a b c] verbose=true",
+		"level=1 outs=[a] verbose=false",
+		"level=3 outs=[a d] verbose=false",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+}
+
 func TestCommand_AddCommand(t *testing.T) {
 	t.Run("dupulicate add panic", func(t *testing.T) {
 		root := &cli.Command{Name: "root"}
@@ -107,6 +268,33 @@ func TestCommand_Lookup(t *testing.T) {
 			t.Errorf("Command.Lookup does not work")
 		}
 	})
+
+	t.Run("after change", func(t *testing.T) {
+		sub, other := &cli.Command{Name: "sub"}, &cli.Command{Name: "other"}
+		root := (&cli.Command{Name: "root"}).AddCommand(sub)
+		if root.Lookup("s") != nil {
+			t.Fatal("alias is not defined yet")
+		}
+		sub.Aliases = []string{"s"}
+		root.Invalidate()
+		if root.Lookup("s") != sub {
+			t.Error("added alias should be found")
+		}
+		sub.Aliases[0] = "x"
+		root.Invalidate()
+		if root.Lookup("s") != nil || root.Lookup("x") != sub {
+			t.Error("changed alias should be reflected")
+		}
+		root.SubCommands[0] = other
+		root.Invalidate()
+		if root.Lookup("sub") != nil || root.Lookup("other") != other {
+			t.Error("replaced sub command should be reflected")
+		}
+		root.SubCommands = append(root.SubCommands, sub)
+		if root.Lookup("sub") != sub {
+			t.Error("appended sub command should be found without Invalidate")
+		}
+	})
 }
 
 func TestCommand_Parse_return_ParseError(t *testing.T) {
@@ -172,6 +360,26 @@ func TestCommand_Parse_return_ParseError(t *testing.T) {
 	}
 }
 
+func TestCommand_Execute_parseErrorDiagnostics(t *testing.T) {
+	var token string
+	var stderr bytes.Buffer
+	root := &cli.Command{Name: "root", Stderr: &stderr}
+	sub := &cli.Command{Name: "sub", Stderr: &stderr, Run: func(_ context.Context, _ *cli.Command, _ []string) {
+		t.Error("Run should not be called")
+	}}
+	sub.Options().Add(&cli.StringOpt{Var: &token, Long: "token", Secret: true})
+	root.AddCommand(sub)
+
+	root.ExecuteWithArgs(context.Background(), []string{"sub", "--token", "s3cr3t", "--unknown=1"})
+	want := `parse error: --unknown unknown flag
+  root sub --token ****** --unknown=1
+                          ^^^^^^^^^^^
+`
+	if diff := cmp.Diff(stderr.String(), want); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+}
+
 func TestParseError_Error(t *testing.T) {
 	t.Run("simple return msg", func(t *testing.T) {
 		err := &cli.ParseError{Message: "parse error"}
@@ -184,9 +392,12 @@ func TestParseError_Error(t *testing.T) {
 func TestHelpFunc(t *testing.T) {
 	want := `when in the go, do as gophers do
 
+Usage:
+  clictl <command>
+
 SubCommands
-      get: get resources
-  version: print version
+  get       get resources
+  version   print version
 `
 	var b strings.Builder
 	cmd := buildCmd(nil, nil, nil)
@@ -197,11 +408,14 @@ SubCommands
 
 	podCmdWant := `get pod long desc...
 
+Usage:
+  clictl get pod [flags]
+
 Options
-  -h, --help : print this
-  -l, --level: level
-      --nums : numbers
-  -v         : verbose
+  -h, --help        print this
+  -l, --level int   level (default 1)
+      --nums ints   numbers
+  -v                verbose
 `
 	podCmd := cmd.Lookup("get").Lookup("pod")
 	if podCmd == nil {
@@ -327,3 +541,62 @@ var buildCmd = func(stdin io.Reader, stdout, stderr io.Writer) *cli.Command {
 			AddCommand(podCmd).
 			AddCommand(rsCmd))
 }
+
+func parseHugeArgs(n int) []string {
+	args := make([]string, 0, n+4)
+	args = append(args, "get", "pod", "--level", "2")
+	for i := 0; i < n; i++ {
+		args = append(args, "file"+strconv.Itoa(i)+".go")
+	}
+	return args
+}
+
+func TestCommand_Parse_hugeArgs(t *testing.T) {
+	cmd := buildCmd(nil, nil, nil)
+	for _, n := range []int{1000, 100000} {
+		args := parseHugeArgs(n)
+		allocs := testing.AllocsPerRun(5, func() {
+			pr, err := cmd.Parse(args)
+			if err != nil || len(pr.Args()) != n {
+				t.Fatalf("Parse() %v, %d args", err, len(pr.Args()))
+			}
+		})
+		// allocations should not depend on the number of arguments.
+		if allocs > 50 {
+			t.Errorf("%d args: %v allocations per Parse", n, allocs)
+		}
+	}
+}
+
+func BenchmarkCommand_Parse(b *testing.B) {
+	cmd := buildCmd(nil, nil, nil)
+	for _, n := range []int{1000, 10000, 100000} {
+		args := parseHugeArgs(n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := cmd.Parse(args); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCommand_Lookup(b *testing.B) {
+	for _, n := range []int{10, 1000, 10000} {
+		root := &cli.Command{Name: "root"}
+		for i := 0; i < n; i++ {
+			root.AddCommand(&cli.Command{Name: "sub" + strconv.Itoa(i), Aliases: []string{"s" + strconv.Itoa(i)}})
+		}
+		name := "s" + strconv.Itoa(n-1)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if root.Lookup(name) == nil {
+					b.Fatal(name + " not found")
+				}
+			}
+		})
+	}
+}
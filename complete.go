@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ymgyt/cli/flags"
+)
+
+// Complete return completion candidates for the last element of args.
+// args does not contain root command name. hidden and deprecated commands and flags are excluded.
+// user aliases are completed and expanded as the first argument. plugins are completed as sub commands.
+func (c *Command) Complete(args []string) []string {
+	c.lasyInit()
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	cmd := c
+	toComplete := args[len(args)-1]
+	if expanded, err := c.expandUserAliases(args[:len(args)-1]); err == nil {
+		args = append(expanded, toComplete)
+	}
+	expectValue, positional := false, false
+	for _, arg := range args[:len(args)-1] {
+		if expectValue {
+			expectValue = false
+			continue
+		}
+		if arg == "--" {
+			return nil
+		}
+		if strings.HasPrefix(arg, "-") {
+			name := strings.TrimLeft(arg, "-")
+			if strings.Contains(name, "=") {
+				continue
+			}
+			if f, err := cmd.lookupFlag(name); err == nil && !f.IsBool() {
+				expectValue = true
+			}
+			continue
+		}
+		if sub := cmd.Lookup(arg); sub != nil {
+			sub.lasyInit()
+			cmd = sub
+		} else {
+			positional = true
+		}
+	}
+	if expectValue {
+		return nil
+	}
+
+	var candidates []string
+	if strings.HasPrefix(toComplete, "-") {
+		cmd.traverseFlags(func(f *flags.Flag) {
+			if f.Hidden || f.Deprecated != "" {
+				return
+			}
+			for _, name := range []string{f.Long, f.Short} {
+				if name != "" && strings.HasPrefix(flagDisplayName(name), toComplete) {
+					candidates = append(candidates, flagDisplayName(name))
+				}
+			}
+		})
+	} else {
+		for _, sub := range cmd.SubCommands {
+			if sub.Hidden || sub.Deprecated != "" {
+				continue
+			}
+			if strings.HasPrefix(sub.Name, toComplete) {
+				candidates = append(candidates, sub.Name)
+			}
+		}
+		if cmd == c && len(args) == 1 {
+			for _, name := range c.userAliasNames() {
+				if strings.HasPrefix(name, toComplete) {
+					candidates = append(candidates, name)
+				}
+			}
+		}
+		if !positional {
+			for _, p := range cmd.ListPlugins() {
+				if strings.HasPrefix(p.Name, toComplete) {
+					candidates = append(candidates, p.Name)
+				}
+			}
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
@@ -0,0 +1,51 @@
+package cli_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli"
+)
+
+func TestCommand_Complete(t *testing.T) {
+	newCmd := func() *cli.Command {
+		var verbose, debug bool
+		var label, old string
+		root := &cli.Command{Name: "root"}
+		get := &cli.Command{Name: "get"}
+		get.Options().
+			Add(&cli.BoolOpt{Var: &verbose, Long: "verbose", Short: "v"}).
+			Add(&cli.BoolOpt{Var: &debug, Long: "debug", Hidden: true}).
+			Add(&cli.StringOpt{Var: &label, Long: "label", Short: "l"}).
+			Add(&cli.StringOpt{Var: &old, Long: "legacy", Deprecated: "use --label"})
+		return root.
+			AddCommand(get).
+			AddCommand(&cli.Command{Name: "gc"}).
+			AddCommand(&cli.Command{Name: "internal", Hidden: true}).
+			AddCommand(&cli.Command{Name: "gone", Deprecated: "no longer supported"})
+	}
+
+	tests := map[string]struct {
+		args []string
+		want []string
+	}{
+		"all sub commands": {args: nil, want: []string{"gc", "get"}},
+		"prefix":           {args: []string{"ge"}, want: []string{"get"}},
+		"flags":            {args: []string{"get", "-"}, want: []string{"--label", "--verbose", "-l", "-v"}},
+		"long flags":       {args: []string{"get", "--l"}, want: []string{"--label"}},
+		"flag value":       {args: []string{"get", "--label", ""}, want: nil},
+		"after flag value": {args: []string{"get", "--label", "app", "--v"}, want: []string{"--verbose"}},
+		"after bool flag":  {args: []string{"get", "-v", "--l"}, want: []string{"--label"}},
+		"after terminator": {args: []string{"get", "--", "-"}, want: nil},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := newCmd().Complete(tc.args)
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("(-got +want)%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package doc
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ChangeKind is a kind of breaking change.
+type ChangeKind string
+
+const (
+	CommandRemoved      ChangeKind = "command_removed"
+	FlagRemoved         ChangeKind = "flag_removed"
+	FlagTypeChanged     ChangeKind = "flag_type_changed"
+	FlagMultipleRemoved ChangeKind = "flag_multiple_removed"
+	FlagEnvVarRemoved   ChangeKind = "flag_env_var_removed"
+	FlagFromFileRemoved ChangeKind = "flag_from_file_removed"
+)
+
+// BreakingChange describes a change which may break existing invocations.
+type BreakingChange struct {
+	Kind ChangeKind `json:"kind"`
+	// Path is the command path in the old schema.
+	Path string `json:"path"`
+	// Flag is the flag name in the old schema if change is about flag.
+	Flag    string `json:"flag,omitempty"`
+	Message string `json:"message"`
+}
+
+func (c *BreakingChange) String() string {
+	if c.Flag != "" {
+		return fmt.Sprintf("%s: %s: %s", c.Path, c.Flag, c.Message)
+	}
+	return fmt.Sprintf("%s: %s", c.Path, c.Message)
+}
+
+// CheckCompatibility compare two schemas and report changes which break invocations valid with old.
+// renamed commands and flags are compatible as long as old names remain as aliases.
+// flags are compared with the ones available in the command, including inherited persistent flags.
+func CheckCompatibility(old, new *CommandSchema) []*BreakingChange {
+	var changes []*BreakingChange
+	checkCommand(old, new, nil, nil, &changes)
+	return changes
+}
+
+func checkCommand(old, new *CommandSchema, oldInherited, newInherited []*FlagSchema, changes *[]*BreakingChange) {
+	oldFlags := availableFlags(old, oldInherited)
+	newFlags := availableFlags(new, newInherited)
+	for _, of := range oldFlags {
+		for _, name := range flagNames(of) {
+			nf := lookupFlag(newFlags, name)
+			if nf == nil {
+				*changes = append(*changes, &BreakingChange{Kind: FlagRemoved, Path: old.Path, Flag: name, Message: "flag removed"})
+				continue
+			}
+			if name != of.Name {
+				continue
+			}
+			if of.Type != nf.Type {
+				*changes = append(*changes, &BreakingChange{
+					Kind: FlagTypeChanged, Path: old.Path, Flag: name,
+					Message: fmt.Sprintf("type changed from %s to %s", of.Type, nf.Type),
+				})
+			}
+			if of.Multiple && !nf.Multiple {
+				*changes = append(*changes, &BreakingChange{Kind: FlagMultipleRemoved, Path: old.Path, Flag: name, Message: "no longer accepts multiple values"})
+			}
+			if of.EnvVar != "" && of.EnvVar != nf.EnvVar {
+				*changes = append(*changes, &BreakingChange{Kind: FlagEnvVarRemoved, Path: old.Path, Flag: name, Message: fmt.Sprintf("env var %s removed", of.EnvVar)})
+			}
+			if of.FromFile && !nf.FromFile {
+				*changes = append(*changes, &BreakingChange{Kind: FlagFromFileRemoved, Path: old.Path, Flag: name, Message: "no longer reads value from file"})
+			}
+		}
+	}
+
+	oldInherited = append(oldInherited, persistentFlags(old)...)
+	newInherited = append(newInherited, persistentFlags(new)...)
+	for _, oldSub := range old.SubCommands {
+		for _, name := range append([]string{oldSub.Name}, oldSub.Aliases...) {
+			newSub := lookupCommand(new, name)
+			if newSub == nil {
+				*changes = append(*changes, &BreakingChange{Kind: CommandRemoved, Path: old.Path + " " + name, Message: "command removed"})
+				continue
+			}
+			if name == oldSub.Name {
+				checkCommand(oldSub, newSub, oldInherited, newInherited, changes)
+			}
+		}
+	}
+}
+
+func availableFlags(cmd *CommandSchema, inherited []*FlagSchema) []*FlagSchema {
+	fs := append([]*FlagSchema{}, cmd.Flags...)
+	fs = append(fs, inherited...)
+	sort.SliceStable(fs, func(i, j int) bool { return fs[i].Name < fs[j].Name })
+	return fs
+}
+
+func persistentFlags(cmd *CommandSchema) []*FlagSchema {
+	var fs []*FlagSchema
+	for _, f := range cmd.Flags {
+		if f.Persistent {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+func flagNames(f *FlagSchema) []string {
+	var names []string
+	for _, name := range append([]string{f.Long, f.Short}, f.Aliases...) {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	aliases := make([]string, 0, len(f.DeprecatedAliases))
+	for alias := range f.DeprecatedAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return append(names, aliases...)
+}
+
+func lookupFlag(fs []*FlagSchema, name string) *FlagSchema {
+	for _, f := range fs {
+		for _, n := range flagNames(f) {
+			if n == name {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+func lookupCommand(parent *CommandSchema, name string) *CommandSchema {
+	for _, sub := range parent.SubCommands {
+		if sub.Name == name {
+			return sub
+		}
+		for _, alias := range sub.Aliases {
+			if alias == name {
+				return sub
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package doc_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli/doc"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	base := func() *doc.CommandSchema { return doc.Export(buildCmd()) }
+
+	tests := map[string]struct {
+		modify func(s *doc.CommandSchema)
+		want   []string
+	}{
+		"same": {
+			modify: func(_ *doc.CommandSchema) {},
+		},
+		"command removed": {
+			modify: func(s *doc.CommandSchema) { s.SubCommands[0].SubCommands = nil },
+			want:   []string{"app sub subsub: command removed"},
+		},
+		"command renamed with alias": {
+			modify: func(s *doc.CommandSchema) {
+				sub := s.SubCommands[0]
+				sub.Name, sub.Aliases = "subcmd", []string{"sub", "s"}
+			},
+		},
+		"command alias removed": {
+			modify: func(s *doc.CommandSchema) { s.SubCommands[0].Aliases = nil },
+			want:   []string{"app s: command removed"},
+		},
+		"flag removed": {
+			modify: func(s *doc.CommandSchema) { s.SubCommands[0].Flags = s.SubCommands[0].Flags[1:] },
+			want:   []string{"app sub: force: flag removed"},
+		},
+		"flag renamed with deprecated alias": {
+			modify: func(s *doc.CommandSchema) {
+				f := s.SubCommands[0].Flags[0]
+				f.Name, f.Long, f.DeprecatedAliases = "force-all", "force-all", map[string]string{"force": "use --force-all"}
+			},
+		},
+		"flag alias removed": {
+			modify: func(s *doc.CommandSchema) { s.SubCommands[0].Flags[1].Aliases = nil },
+			want:   []string{"app sub: limit: flag removed"},
+		},
+		"flag type changed": {
+			modify: func(s *doc.CommandSchema) { s.SubCommands[0].Flags[1].Type = "string" },
+			want:   []string{"app sub: max: type changed from int to string"},
+		},
+		"env var removed": {
+			modify: func(s *doc.CommandSchema) { s.SubCommands[0].Flags[1].EnvVar = "" },
+			want:   []string{"app sub: max: env var APP_MAX removed"},
+		},
+		"persistent flag became local": {
+			modify: func(s *doc.CommandSchema) { s.Flags[0].Persistent = false },
+			want: []string{
+				"app sub: verbose: flag removed",
+				"app sub: v: flag removed",
+				"app sub subsub: verbose: flag removed",
+				"app sub subsub: v: flag removed",
+				"app internal: verbose: flag removed",
+				"app internal: v: flag removed",
+			},
+		},
+		"local flag became persistent": {
+			modify: func(s *doc.CommandSchema) {
+				sub := s.SubCommands[0]
+				s.Flags, sub.Flags = append(s.Flags, sub.Flags[0]), sub.Flags[1:]
+				s.Flags[1].Persistent = true
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			new := base()
+			tc.modify(new)
+			var got []string
+			for _, c := range doc.CheckCompatibility(base(), new) {
+				got = append(got, c.String())
+			}
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("(-got +want)%s", diff)
+			}
+		})
+	}
+}
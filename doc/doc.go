@@ -0,0 +1,99 @@
+// Package doc generates documents like man pages from cli.Command tree.
+package doc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/ymgyt/cli"
+)
+
+// baseName return command path joined by hyphen like "app-sub-subsub".
+func baseName(cmd *cli.Command) string {
+	return strings.Replace(cmd.Path(), " ", "-", -1)
+}
+
+// visibleSubCommands return sub commands which are not hidden.
+func visibleSubCommands(cmd *cli.Command) []*cli.Command {
+	var subs []*cli.Command
+	for _, sub := range cmd.SubCommands {
+		if !sub.Hidden {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// walk call fn with cmd and its visible descendants.
+func walk(cmd *cli.Command, fn func(*cli.Command) error) error {
+	if err := fn(cmd); err != nil {
+		return err
+	}
+	for _, sub := range visibleSubCommands(cmd) {
+		if err := walk(sub, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Options customizes markdown and reStructuredText generation.
+type Options struct {
+	// FrontMatter returns text written at the top of the document of cmd.
+	// filename is empty when documents are combined into a single file.
+	FrontMatter func(cmd *cli.Command, filename string) string
+	// Link converts document file name to link target. default is the file name as it is.
+	Link func(filename string) string
+}
+
+func (o *Options) frontMatter(cmd *cli.Command, filename string) string {
+	if o == nil || o.FrontMatter == nil {
+		return ""
+	}
+	return o.FrontMatter(cmd, filename)
+}
+
+func (o *Options) link(filename string) string {
+	if o == nil || o.Link == nil {
+		return filename
+	}
+	return o.Link(filename)
+}
+
+// related return parent and visible sub commands of cmd.
+func related(cmd *cli.Command) []*cli.Command {
+	var cmds []*cli.Command
+	if parent := cmd.Parent(); parent != nil {
+		cmds = append(cmds, parent)
+	}
+	return append(cmds, visibleSubCommands(cmd)...)
+}
+
+// flagLines format flags of group like help message.
+func flagLines(data *cli.HelpData, group *cli.HelpFlagGroup) []string {
+	lines := make([]string, 0, len(group.Flags))
+	for _, f := range group.Flags {
+		if f.Usage == "" {
+			lines = append(lines, f.Names)
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%-*s   %s", data.FlagWidth, f.Names, f.Usage))
+	}
+	return lines
+}
+
+// writeTree writes a document per command into dir by gen.
+func writeTree(cmd *cli.Command, dir, ext string, gen func(*cli.Command, string, io.Writer) error) error {
+	return walk(cmd, func(c *cli.Command) error {
+		filename := baseName(c) + ext
+		var b bytes.Buffer
+		if err := gen(c, filename, &b); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dir, filename), b.Bytes(), 0644)
+	})
+}
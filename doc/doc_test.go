@@ -0,0 +1,37 @@
+package doc_test
+
+import (
+	"context"
+
+	"github.com/ymgyt/cli"
+)
+
+func buildCmd() *cli.Command {
+	var verbose, force bool
+	var max int
+	var output string
+	root := &cli.Command{Name: "app", ShortDesc: "app example", LongDesc: "app is an example.\n.dot line"}
+	root.PersistentOptions().
+		Add(&cli.BoolOpt{Var: &verbose, Long: "verbose", Short: "v", Description: "verbose output"})
+
+	sub := &cli.Command{
+		Name:      "sub",
+		Aliases:   []string{"s"},
+		ShortDesc: "sub command",
+		Examples:  []*cli.Example{{Description: "run sub", Command: "app sub --max=10"}},
+	}
+	sub.Options().
+		Add(&cli.BoolOpt{Var: &force, Long: "force", Description: "force"}).
+		Add(&cli.IntOpt{Var: &max, Long: "max", Aliases: []string{"limit"}, Default: 5, EnvVar: "APP_MAX", Description: "maximum"})
+
+	subsub := &cli.Command{
+		Name:      "subsub",
+		ShortDesc: "subsub command",
+		Run:       func(_ context.Context, _ *cli.Command, _ []string) {},
+	}
+	subsub.Options().Add(&cli.StringOpt{Var: &output, Long: "output", Short: "o", Description: "output format"})
+
+	return root.
+		AddCommand(sub.AddCommand(subsub)).
+		AddCommand(&cli.Command{Name: "internal", Hidden: true})
+}
@@ -0,0 +1,159 @@
+package doc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ymgyt/cli"
+)
+
+// ManHeader is the header of man pages.
+type ManHeader struct {
+	// Section is a man section. default is "1".
+	Section string
+	// Date is displayed in footer. default is current time.
+	Date   time.Time
+	Source string
+	Manual string
+}
+
+func (h *ManHeader) section() string {
+	if h == nil || h.Section == "" {
+		return "1"
+	}
+	return h.Section
+}
+
+// GenManTree writes man pages of cmd and its sub commands into dir.
+// file names are command path joined by hyphen like "app-sub-subsub.1". hidden commands are skipped.
+func GenManTree(cmd *cli.Command, header *ManHeader, dir string) error {
+	return writeTree(cmd, dir, "."+header.section(), func(c *cli.Command, _ string, w io.Writer) error {
+		return GenMan(c, header, w)
+	})
+}
+
+// GenMan writes roff man page of cmd to w.
+func GenMan(cmd *cli.Command, header *ManHeader, w io.Writer) error {
+	if header == nil {
+		header = &ManHeader{}
+	}
+	date := header.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	data := cmd.HelpData()
+	name := baseName(cmd)
+	section := header.section()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH %q %q %q %q %q\n",
+		strings.ToUpper(name), section, date.Format("Jan 2006"), header.Source, header.Manual)
+
+	b.WriteString(".SH NAME\n")
+	b.WriteString(roffEscape(name))
+	if data.ShortDesc != "" {
+		b.WriteString(` \- ` + roffEscape(data.ShortDesc))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&b, "\\fB%s\\fP%s\n", roffEscape(data.Path), roffEscape(strings.TrimPrefix(data.Usage, data.Path)))
+
+	if desc := data.LongDesc; desc != "" || data.ShortDesc != "" {
+		if desc == "" {
+			desc = data.ShortDesc
+		}
+		b.WriteString(".SH DESCRIPTION\n")
+		b.WriteString(roffText(desc) + "\n")
+	}
+
+	if len(data.Aliases) > 0 {
+		b.WriteString(".SH ALIASES\n")
+		b.WriteString(roffEscape(strings.Join(data.Aliases, ", ")) + "\n")
+	}
+
+	for _, group := range data.FlagGroups {
+		b.WriteString(".SH " + strings.ToUpper(roffEscape(group.Title)) + "\n")
+		for _, f := range group.Flags {
+			b.WriteString(".TP\n")
+			b.WriteString(manFlagNames(f) + "\n")
+			var lines []string
+			if f.Description != "" {
+				lines = append(lines, roffText(f.Description))
+			}
+			if f.Default != "" {
+				lines = append(lines, "Default: "+roffEscape(f.Default))
+			}
+			if f.EnvVar != "" {
+				lines = append(lines, "Environment: \\fB"+roffEscape(f.EnvVar)+"\\fP")
+			}
+			b.WriteString(strings.Join(lines, "\n.br\n") + "\n")
+		}
+	}
+
+	if len(data.Examples) > 0 {
+		b.WriteString(".SH EXAMPLES\n")
+		for _, ex := range data.Examples {
+			if ex.Description != "" {
+				b.WriteString(".PP\n" + roffText(ex.Description) + "\n")
+			}
+			b.WriteString(".PP\n.RS\n.nf\n" + roffText(ex.Command) + "\n.fi\n.RE\n")
+		}
+	}
+
+	var seeAlso []string
+	if parent := cmd.Parent(); parent != nil {
+		seeAlso = append(seeAlso, fmt.Sprintf("\\fB%s\\fP(%s)", roffEscape(baseName(parent)), section))
+	}
+	for _, sub := range visibleSubCommands(cmd) {
+		seeAlso = append(seeAlso, fmt.Sprintf("\\fB%s\\fP(%s)", roffEscape(baseName(sub)), section))
+	}
+	if len(seeAlso) > 0 {
+		b.WriteString(".SH SEE ALSO\n")
+		b.WriteString(strings.Join(seeAlso, ", ") + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func manFlagNames(f *cli.HelpFlag) string {
+	var names []string
+	if f.Short != "" {
+		names = append(names, "\\fB\\-"+roffEscape(f.Short)+"\\fP")
+	}
+	if f.Long != "" {
+		names = append(names, "\\fB\\-\\-"+roffEscape(f.Long)+"\\fP")
+	}
+	for _, alias := range f.Aliases {
+		prefix := "\\-\\-"
+		if len(alias) == 1 {
+			prefix = "\\-"
+		}
+		names = append(names, "\\fB"+prefix+roffEscape(alias)+"\\fP")
+	}
+	s := strings.Join(names, ", ")
+	if f.Type != "" && f.Type != "bool" {
+		s += " \\fI" + roffEscape(f.Type) + "\\fP"
+	}
+	return s
+}
+
+// roffEscape escape backslashes and hyphens.
+func roffEscape(s string) string {
+	s = strings.Replace(s, `\`, `\e`, -1)
+	return strings.Replace(s, "-", `\-`, -1)
+}
+
+// roffText escape s and protect lines starting with control characters.
+func roffText(s string) string {
+	lines := strings.Split(roffEscape(s), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
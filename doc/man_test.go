@@ -0,0 +1,99 @@
+package doc_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli/doc"
+)
+
+func TestGenMan(t *testing.T) {
+	header := &doc.ManHeader{
+		Date:   time.Date(2019, 11, 22, 0, 0, 0, 0, time.UTC),
+		Source: "app v1.0.0",
+		Manual: "App Manual",
+	}
+	var b bytes.Buffer
+	if err := doc.GenMan(buildCmd().Lookup("sub"), header, &b); err != nil {
+		t.Fatal(err)
+	}
+	want := `.TH "APP-SUB" "1" "Nov 2019" "app v1.0.0" "App Manual"
+.SH NAME
+app\-sub \- sub command
+.SH SYNOPSIS
+\fBapp sub\fP [flags] <command>
+.SH DESCRIPTION
+sub command
+.SH ALIASES
+sub, s
+.SH OPTIONS
+.TP
+\fB\-\-force\fP
+force
+.TP
+\fB\-\-max\fP, \fB\-\-limit\fP \fIint\fP
+maximum
+.br
+Default: 5
+.br
+Environment: \fBAPP_MAX\fP
+.SH GLOBAL OPTIONS
+.TP
+\fB\-v\fP, \fB\-\-verbose\fP
+verbose output
+.SH EXAMPLES
+.PP
+run sub
+.PP
+.RS
+.nf
+app sub \-\-max=10
+.fi
+.RE
+.SH SEE ALSO
+\fBapp\fP(1), \fBapp\-sub\-subsub\fP(1)
+`
+	if diff := cmp.Diff(b.String(), want); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+}
+
+func TestGenMan_escape(t *testing.T) {
+	var b bytes.Buffer
+	if err := doc.GenMan(buildCmd(), nil, &b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b.Bytes(), []byte("\n\\&.dot line\n")) {
+		t.Errorf("line starting with dot should be escaped. got %s", b.String())
+	}
+}
+
+func TestGenManTree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "man")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := doc.GenManTree(buildCmd(), &doc.ManHeader{Section: "8"}, dir); err != nil {
+		t.Fatal(err)
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, info := range infos {
+		got = append(got, info.Name())
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff(got, []string{"app-sub-subsub.8", "app-sub.8", "app.8"}); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+}
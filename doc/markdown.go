@@ -0,0 +1,100 @@
+package doc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ymgyt/cli"
+)
+
+const markdownExt = ".md"
+
+// GenMarkdown writes markdown document of cmd to w.
+func GenMarkdown(cmd *cli.Command, opts *Options, w io.Writer) error {
+	filename := baseName(cmd) + markdownExt
+	return writeString(w, opts.frontMatter(cmd, filename)+markdownSection(cmd, opts, false))
+}
+
+// GenMarkdownTree writes markdown documents of cmd and its sub commands into dir.
+// file names are command path joined by hyphen like "app-sub.md". hidden commands are skipped.
+func GenMarkdownTree(cmd *cli.Command, opts *Options, dir string) error {
+	return writeTree(cmd, dir, markdownExt, func(c *cli.Command, filename string, w io.Writer) error {
+		return writeString(w, opts.frontMatter(c, filename)+markdownSection(c, opts, false))
+	})
+}
+
+// GenMarkdownSingle writes markdown documents of cmd and its sub commands into w as a single document.
+// links refer to the sections in the document.
+func GenMarkdownSingle(cmd *cli.Command, opts *Options, w io.Writer) error {
+	var sections []string
+	err := walk(cmd, func(c *cli.Command) error {
+		sections = append(sections, markdownSection(c, opts, true))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writeString(w, opts.frontMatter(cmd, "")+strings.Join(sections, "\n"))
+}
+
+func markdownSection(cmd *cli.Command, opts *Options, single bool) string {
+	data := cmd.HelpData()
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", data.Path)
+	if data.ShortDesc != "" {
+		b.WriteString(data.ShortDesc + "\n\n")
+	}
+
+	b.WriteString("### Synopsis\n\n")
+	if data.LongDesc != "" {
+		b.WriteString(data.LongDesc + "\n\n")
+	}
+	b.WriteString(markdownCode([]string{data.Usage}))
+
+	if len(data.Aliases) > 0 {
+		b.WriteString("### Aliases\n\n" + strings.Join(data.Aliases, ", ") + "\n\n")
+	}
+
+	if len(data.Examples) > 0 {
+		var lines []string
+		for _, ex := range data.Examples {
+			if ex.Description != "" {
+				lines = append(lines, "# "+ex.Description)
+			}
+			lines = append(lines, ex.Command)
+		}
+		b.WriteString("### Examples\n\n" + markdownCode(lines))
+	}
+
+	for _, group := range data.FlagGroups {
+		b.WriteString("### " + group.Title + "\n\n" + markdownCode(flagLines(data, group)))
+	}
+
+	if cmds := related(cmd); len(cmds) > 0 {
+		b.WriteString("### See Also\n\n")
+		for _, c := range cmds {
+			link := opts.link(baseName(c) + markdownExt)
+			if single {
+				link = "#" + baseName(c)
+			}
+			fmt.Fprintf(&b, "* [%s](%s)", c.Path(), link)
+			if c.ShortDesc != "" {
+				b.WriteString(" - " + c.ShortDesc)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func markdownCode(lines []string) string {
+	return "```\n" + strings.Join(lines, "\n") + "\n```\n\n"
+}
+
+func writeString(w io.Writer, s string) error {
+	_, err := io.WriteString(w, s)
+	return err
+}
@@ -0,0 +1,111 @@
+package doc_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli"
+	"github.com/ymgyt/cli/doc"
+)
+
+func TestGenMarkdown(t *testing.T) {
+	opts := &doc.Options{
+		FrontMatter: func(cmd *cli.Command, filename string) string {
+			return "---\ntitle: " + cmd.Path() + "\nfile: " + filename + "\n---\n\n"
+		},
+		Link: func(filename string) string { return "/commands/" + strings.TrimSuffix(filename, ".md") + "/" },
+	}
+	var b bytes.Buffer
+	if err := doc.GenMarkdown(buildCmd().Lookup("sub"), opts, &b); err != nil {
+		t.Fatal(err)
+	}
+	want := "---\ntitle: app sub\nfile: app-sub.md\n---\n\n" + `## app sub
+
+sub command
+
+### Synopsis
+
+` + "```" + `
+app sub [flags] <command>
+` + "```" + `
+
+### Aliases
+
+sub, s
+
+### Examples
+
+` + "```" + `
+# run sub
+app sub --max=10
+` + "```" + `
+
+### Options
+
+` + "```" + `
+    --force              force
+    --max, --limit int   maximum (default 5) [$APP_MAX]
+` + "```" + `
+
+### Global Options
+
+` + "```" + `
+-v, --verbose            verbose output
+` + "```" + `
+
+### See Also
+
+* [app](/commands/app/) - app example
+* [app sub subsub](/commands/app-sub-subsub/) - subsub command
+`
+	if diff := cmp.Diff(b.String(), want); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+}
+
+func TestGenMarkdownTree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := doc.GenMarkdownTree(buildCmd(), nil, dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"app.md", "app-sub.md", "app-sub-subsub.md"} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(b, []byte("## app")) {
+			t.Errorf("%s: unexpected content %s", name, b)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app-internal.md")); !os.IsNotExist(err) {
+		t.Errorf("hidden command document should not be generated")
+	}
+}
+
+func TestGenMarkdownSingle(t *testing.T) {
+	var b bytes.Buffer
+	opts := &doc.Options{FrontMatter: func(_ *cli.Command, _ string) string { return "# App\n\n" }}
+	if err := doc.GenMarkdownSingle(buildCmd(), opts, &b); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+	if !strings.HasPrefix(got, "# App\n\n## app\n") {
+		t.Errorf("front matter should be written once at the top. got %s", got)
+	}
+	for _, want := range []string{"\n## app sub\n", "\n## app sub subsub\n", "* [app sub](#app-sub) - sub command"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %q in %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package doc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ymgyt/cli"
+)
+
+const rstExt = ".rst"
+
+// GenReST writes reStructuredText document of cmd to w.
+func GenReST(cmd *cli.Command, opts *Options, w io.Writer) error {
+	filename := baseName(cmd) + rstExt
+	return writeString(w, opts.frontMatter(cmd, filename)+rstSection(cmd, opts, false))
+}
+
+// GenReSTTree writes reStructuredText documents of cmd and its sub commands into dir.
+// file names are command path joined by hyphen like "app-sub.rst". hidden commands are skipped.
+func GenReSTTree(cmd *cli.Command, opts *Options, dir string) error {
+	return writeTree(cmd, dir, rstExt, func(c *cli.Command, filename string, w io.Writer) error {
+		return writeString(w, opts.frontMatter(c, filename)+rstSection(c, opts, false))
+	})
+}
+
+// GenReSTSingle writes reStructuredText documents of cmd and its sub commands into w as a single document.
+// links refer to the sections in the document.
+func GenReSTSingle(cmd *cli.Command, opts *Options, w io.Writer) error {
+	var sections []string
+	err := walk(cmd, func(c *cli.Command) error {
+		sections = append(sections, rstSection(c, opts, true))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writeString(w, opts.frontMatter(cmd, "")+strings.Join(sections, "\n"))
+}
+
+func rstSection(cmd *cli.Command, opts *Options, single bool) string {
+	data := cmd.HelpData()
+	var b strings.Builder
+	b.WriteString(rstHeading(data.Path, "="))
+	if data.ShortDesc != "" {
+		b.WriteString(data.ShortDesc + "\n\n")
+	}
+
+	// sub section titles are prefixed by command path to be unique in a single document.
+	heading := func(title string) string {
+		if single {
+			title = data.Path + " " + strings.ToLower(title)
+		}
+		return rstHeading(title, "-")
+	}
+
+	b.WriteString(heading("Synopsis"))
+	if data.LongDesc != "" {
+		b.WriteString(data.LongDesc + "\n\n")
+	}
+	b.WriteString(rstLiteral([]string{data.Usage}))
+
+	if len(data.Aliases) > 0 {
+		b.WriteString(heading("Aliases") + strings.Join(data.Aliases, ", ") + "\n\n")
+	}
+
+	if len(data.Examples) > 0 {
+		var lines []string
+		for _, ex := range data.Examples {
+			if ex.Description != "" {
+				lines = append(lines, "# "+ex.Description)
+			}
+			lines = append(lines, ex.Command)
+		}
+		b.WriteString(heading("Examples") + rstLiteral(lines))
+	}
+
+	for _, group := range data.FlagGroups {
+		b.WriteString(heading(group.Title) + rstLiteral(flagLines(data, group)))
+	}
+
+	if cmds := related(cmd); len(cmds) > 0 {
+		b.WriteString(heading("See Also"))
+		for _, c := range cmds {
+			if single {
+				fmt.Fprintf(&b, "* `%s`_", c.Path())
+			} else {
+				fmt.Fprintf(&b, "* `%s <%s>`_", c.Path(), opts.link(baseName(c)+rstExt))
+			}
+			if c.ShortDesc != "" {
+				b.WriteString(" - " + c.ShortDesc)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func rstHeading(title, underline string) string {
+	return title + "\n" + strings.Repeat(underline, len(title)) + "\n\n"
+}
+
+func rstLiteral(lines []string) string {
+	return "::\n\n" + indent("   ", lines) + "\n\n"
+}
+
+func indent(prefix string, lines []string) string {
+	indented := make([]string, 0, len(lines))
+	for _, line := range lines {
+		indented = append(indented, prefix+line)
+	}
+	return strings.Join(indented, "\n")
+}
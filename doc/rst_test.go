@@ -0,0 +1,84 @@
+package doc_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli/doc"
+)
+
+func TestGenReST(t *testing.T) {
+	var b bytes.Buffer
+	if err := doc.GenReST(buildCmd().Lookup("sub").Lookup("subsub"), nil, &b); err != nil {
+		t.Fatal(err)
+	}
+	want := `app sub subsub
+==============
+
+subsub command
+
+Synopsis
+--------
+
+::
+
+   app sub subsub [flags]
+
+Options
+-------
+
+::
+
+   -o, --output string   output format
+
+Global Options
+--------------
+
+::
+
+   -v, --verbose         verbose output
+
+See Also
+--------
+
+` + "* `app sub <app-sub.rst>`_ - sub command\n"
+	if diff := cmp.Diff(b.String(), want); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+}
+
+func TestGenReSTTree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := doc.GenReSTTree(buildCmd(), nil, dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"app.rst", "app-sub.rst", "app-sub-subsub.rst"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestGenReSTSingle(t *testing.T) {
+	var b bytes.Buffer
+	if err := doc.GenReSTSingle(buildCmd(), nil, &b); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+	for _, want := range []string{"app sub\n=======\n", "app sub options\n---------------\n", "* `app sub`_ - sub command"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %q in %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package doc
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ymgyt/cli"
+	"github.com/ymgyt/cli/flags"
+)
+
+// CommandSchema is a machine readable definition of a command and its sub commands.
+type CommandSchema struct {
+	Name        string           `json:"name"`
+	Path        string           `json:"path"`
+	Aliases     []string         `json:"aliases,omitempty"`
+	ShortDesc   string           `json:"short_desc,omitempty"`
+	LongDesc    string           `json:"long_desc,omitempty"`
+	Hidden      bool             `json:"hidden,omitempty"`
+	Deprecated  string           `json:"deprecated,omitempty"`
+	GroupID     string           `json:"group_id,omitempty"`
+	Flags       []*FlagSchema    `json:"flags,omitempty"`
+	Examples    []*cli.Example   `json:"examples,omitempty"`
+	SubCommands []*CommandSchema `json:"sub_commands,omitempty"`
+}
+
+// FlagSchema is a machine readable definition of a flag.
+type FlagSchema struct {
+	Name              string            `json:"name"`
+	Long              string            `json:"long,omitempty"`
+	Short             string            `json:"short,omitempty"`
+	Aliases           []string          `json:"aliases,omitempty"`
+	DeprecatedAliases map[string]string `json:"deprecated_aliases,omitempty"`
+	Type              string            `json:"type"`
+	Default           string            `json:"default,omitempty"`
+	EnvVar            string            `json:"env_var,omitempty"`
+	Description       string            `json:"description,omitempty"`
+	Category          string            `json:"category,omitempty"`
+	// Persistent flag is inherited by sub commands.
+	Persistent bool `json:"persistent,omitempty"`
+	// Multiple flag can be given multiple times.
+	Multiple   bool   `json:"multiple,omitempty"`
+	Delimiter  string `json:"delimiter,omitempty"`
+	FromFile   bool   `json:"from_file,omitempty"`
+	Secret     bool   `json:"secret,omitempty"`
+	Hidden     bool   `json:"hidden,omitempty"`
+	Deprecated string `json:"deprecated,omitempty"`
+	ReplacedBy string `json:"replaced_by,omitempty"`
+}
+
+// Export build schema of cmd and its sub commands. hidden ones are also included.
+func Export(cmd *cli.Command) *CommandSchema {
+	s := &CommandSchema{
+		Name:       cmd.Name,
+		Path:       cmd.Path(),
+		Aliases:    cmd.Aliases,
+		ShortDesc:  cmd.ShortDesc,
+		LongDesc:   cmd.LongDesc,
+		Hidden:     cmd.Hidden,
+		Deprecated: cmd.Deprecated,
+		GroupID:    cmd.GroupID,
+		Examples:   cmd.Examples,
+	}
+	for _, f := range cmd.Flags() {
+		s.Flags = append(s.Flags, newFlagSchema(f, false))
+	}
+	for _, f := range cmd.PersistentFlags() {
+		s.Flags = append(s.Flags, newFlagSchema(f, true))
+	}
+	for _, sub := range cmd.SubCommands {
+		s.SubCommands = append(s.SubCommands, Export(sub))
+	}
+	return s
+}
+
+func newFlagSchema(f *flags.Flag, persistent bool) *FlagSchema {
+	fs := &FlagSchema{
+		Name:              f.Name(),
+		Long:              f.Long,
+		Short:             f.Short,
+		Aliases:           f.Aliases,
+		DeprecatedAliases: f.DeprecatedAliases,
+		Type:              f.Type(),
+		Default:           f.Default,
+		EnvVar:            f.EnvVar,
+		Description:       f.Description,
+		Category:          f.Category,
+		Persistent:        persistent,
+		Multiple:          f.AllowMultipleTimesSet,
+		Delimiter:         f.Delimiter,
+		FromFile:          f.FromFile,
+		Secret:            f.Secret,
+		Hidden:            f.Hidden,
+		Deprecated:        f.Deprecated,
+		ReplacedBy:        f.ReplacedBy,
+	}
+	if f.Secret {
+		fs.Default = ""
+	}
+	return fs
+}
+
+// GenJSON writes schema of cmd to w as indented JSON.
+func GenJSON(cmd *cli.Command, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Export(cmd))
+}
+
+// ReadJSON reads schema written by GenJSON.
+func ReadJSON(r io.Reader) (*CommandSchema, error) {
+	s := &CommandSchema{}
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
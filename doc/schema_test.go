@@ -0,0 +1,50 @@
+package doc_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli/doc"
+)
+
+func TestExport(t *testing.T) {
+	s := doc.Export(buildCmd())
+	if s.Name != "app" || len(s.SubCommands) != 2 {
+		t.Fatalf("unexpected schema %+v", s)
+	}
+	if got := s.Flags[0]; !got.Persistent || got.Name != "verbose" || got.Type != "bool" {
+		t.Errorf("unexpected persistent flag %+v", got)
+	}
+	sub := s.SubCommands[0]
+	want := &doc.FlagSchema{
+		Name:        "max",
+		Long:        "max",
+		Aliases:     []string{"limit"},
+		Type:        "int",
+		Default:     "5",
+		EnvVar:      "APP_MAX",
+		Description: "maximum",
+	}
+	if diff := cmp.Diff(sub.Flags[1], want); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+	if !s.SubCommands[1].Hidden {
+		t.Error("hidden command should be exported with hidden property")
+	}
+}
+
+func TestGenJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := doc.GenJSON(buildCmd(), &b); err != nil {
+		t.Fatal(err)
+	}
+	got, err := doc.ReadJSON(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, doc.Export(buildCmd())); diff != "" {
+		t.Errorf("JSON round trip does not match. (-got +want)%s", diff)
+	}
+}
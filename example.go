@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ymgyt/cli/parser"
+)
+
+// Example is a usage example of command.
+type Example struct {
+	Description string `json:"description,omitempty"`
+	// Command is a command line including root command name like "app get pod --level=2".
+	Command string `json:"command"`
+}
+
+// ExampleError describes the example which does not parse.
+type ExampleError struct {
+	// Path is a path of the command which has the example.
+	Path    string
+	Example *Example
+	Err     error
+}
+
+func (e *ExampleError) Error() string {
+	return fmt.Sprintf("%s: example %q: %s", e.Path, e.Example.Command, e.Err)
+}
+
+// ExampleErrors is returned by Command.ValidateExamples.
+type ExampleErrors []*ExampleError
+
+func (es ExampleErrors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// ValidateExamples parse examples of the command and its sub commands with current flag definitions.
+// it is intended to be called from tests so that examples does not get outdated.
+func (c *Command) ValidateExamples() error {
+	var errs ExampleErrors
+	c.walk(func(cmd *Command) {
+		for _, ex := range cmd.Examples {
+			if err := cmd.validateExample(ex); err != nil {
+				errs = append(errs, &ExampleError{Path: cmd.Path(), Example: ex, Err: err})
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (c *Command) validateExample(ex *Example) error {
+	root := c.root()
+	args, err := parser.SplitWords(ex.Command)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 || args[0] != root.Name {
+		return fmt.Errorf("example should start with %s", root.Name)
+	}
+	pr, err := root.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+	cmds, owner := root.resolve(pr)
+	runCmd := cmds[len(cmds)-1]
+	if !runCmd.isDescendantOf(c) {
+		return fmt.Errorf("%s is not %s or its sub command", runCmd.Path(), c.Path())
+	}
+	for _, pf := range pr.AllFlags() {
+		cmd := owner(pf)
+		f, err := cmd.lookupFlag(pf.Name)
+		if err != nil {
+			return fmt.Errorf("flag %s not found", flagDisplayName(pf.Name))
+		}
+		if replacedBy := f.ReplacedBy; replacedBy != "" {
+			if f, err = cmd.lookupFlag(replacedBy); err != nil {
+				return fmt.Errorf("flag %s not found", replacedBy)
+			}
+		}
+		if pf.IsBool {
+			continue
+		}
+		// value is checked with a throwaway Var so that examples do not change flags.
+		if err := f.CheckValue(pf.Value); err != nil {
+			return fmt.Errorf("flag %s: %s", flagDisplayName(pf.Name), err)
+		}
+	}
+	return nil
+}
+
+// walk call fn with the command and its descendants in depth first order.
+func (c *Command) walk(fn func(*Command)) {
+	c.lasyInit()
+	fn(c)
+	for _, sub := range c.SubCommands {
+		sub.walk(fn)
+	}
+}
+
+func (c *Command) root() *Command {
+	root := c
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
+
+func (c *Command) isDescendantOf(ancestor *Command) bool {
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if cmd == ancestor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package cli_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli"
+)
+
+func TestCommand_ValidateExamples(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		cmd := buildCmd(nil, nil, nil)
+		get := cmd.Lookup("get")
+		get.Examples = []*cli.Example{
+			{Description: "get pods", Command: "clictl get pod --level=2 -v"},
+			{Command: "clictl get rs"},
+			{Description: "quoted argument", Command: `clictl get pod 'app name' --level="3"`},
+		}
+		if err := cmd.ValidateExamples(); err != nil {
+			t.Errorf("ValidateExamples() %v", err)
+		}
+	})
+
+	t.Run("flag of ancestor", func(t *testing.T) {
+		var verbose bool
+		root := &cli.Command{Name: "app"}
+		root.Options().Add(&cli.BoolOpt{Var: &verbose, Long: "verbose"})
+		sub := &cli.Command{Name: "sub", Run: func(_ context.Context, _ *cli.Command, _ []string) {}}
+		sub.Examples = []*cli.Example{{Command: "app --verbose sub"}}
+		root.AddCommand(sub)
+		if err := root.ValidateExamples(); err != nil {
+			t.Errorf("ValidateExamples() %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		cmd := buildCmd(nil, nil, nil)
+		cmd.Examples = []*cli.Example{{Command: "kubectl get pod"}}
+		pod := cmd.Lookup("get").Lookup("pod")
+		pod.Examples = []*cli.Example{
+			{Command: "clictl get pod --removed=x"},
+			{Command: "clictl get pod --level"},
+			{Command: "clictl get pod --level=abc"},
+			{Command: "clictl get pod --nums=1,x"},
+			{Command: "clictl version"},
+			{Command: `clictl get pod "unterminated`},
+		}
+		err := cmd.ValidateExamples()
+		errs, ok := err.(cli.ExampleErrors)
+		if !ok {
+			t.Fatalf("want ExampleErrors, got %v", err)
+		}
+		var got []string
+		for _, e := range errs {
+			got = append(got, e.Example.Command)
+		}
+		want := []string{"kubectl get pod", "clictl get pod --removed=x", "clictl get pod --level", "clictl get pod --level=abc", "clictl get pod --nums=1,x", "clictl version", `clictl get pod "unterminated`}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+		if !strings.Contains(err.Error(), "flag --removed not found") {
+			t.Errorf("error message should contain the reason. got %s", err)
+		}
+	})
+}
+
+func TestHelpFunc_examples(t *testing.T) {
+	cmd := &cli.Command{
+		Name: "app",
+		Examples: []*cli.Example{
+			{Description: "run with default config", Command: "app"},
+			{Command: "app --help"},
+		},
+	}
+	want := `Usage:
+  app
+
+Examples
+  # run with default config
+  app
+  app --help
+`
+	var b strings.Builder
+	cli.HelpFunc(&b, cmd)
+	if diff := cmp.Diff(b.String(), want); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+}
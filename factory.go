@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"context"
+	"io"
+)
+
+// Factory builds a new command tree with fresh flag storage on each call.
+// option Vars should be declared inside the factory so that each tree owns its values.
+// trees built by a factory can be executed concurrently, while a tree itself is not safe for concurrent execution.
+type Factory func() *Command
+
+// New build a command tree whose commands use given stdin, stdout and stderr.
+func (f Factory) New(stdin io.Reader, stdout, stderr io.Writer) *Command {
+	root := f()
+	root.walk(func(cmd *Command) {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, stderr
+	})
+	return root
+}
+
+// ExecuteWithArgs build a command tree and execute it with args.
+func (f Factory) ExecuteWithArgs(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) {
+	f.New(stdin, stdout, stderr).ExecuteWithArgs(ctx, args)
+}
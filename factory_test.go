@@ -0,0 +1,64 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ymgyt/cli"
+)
+
+func TestFactory_concurrent(t *testing.T) {
+	factory := cli.Factory(func() *cli.Command {
+		var verbose bool
+		var level int
+		var outs []string
+		root := &cli.Command{Name: "app"}
+		root.PersistentOptions().Add(&cli.BoolOpt{Var: &verbose, Long: "verbose"})
+		sub := &cli.Command{Name: "sub", Run: func(_ context.Context, cmd *cli.Command, args []string) {
+			fmt.Fprintf(cmd.Stdout, "level=%d outs=%v verbose=%v args=%v", level, outs, verbose, args)
+		}}
+		sub.Options().
+			Add(&cli.IntOpt{Var: &level, Long: "level", Default: 1}).
+			Add(&cli.StringsOpt{Var: &outs, Long: "outs"})
+		return root.AddCommand(sub)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			n := strconv.Itoa(i)
+			var stdout, stderr bytes.Buffer
+			args := []string{"--level=" + n, "sub", "--outs", n, "--verbose", n}
+			factory.ExecuteWithArgs(context.Background(), args, strings.NewReader(""), &stdout, &stderr)
+			want := fmt.Sprintf("level=%d outs=[%d] verbose=true args=[%d]", i, i, i)
+			if stdout.String() != want || stderr.Len() > 0 {
+				t.Errorf("got %q, stderr %q, want %q", stdout.String(), stderr.String(), want)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestCommand_concurrentReadOnly(t *testing.T) {
+	cmd := buildCmd(nil, &bytes.Buffer{}, &bytes.Buffer{})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cmd.Parse([]string{"get", "pod", "-vh", "--level", "2", "a"}); err != nil {
+				t.Error(err)
+			}
+			cmd.Complete([]string{"get", "pod", "--"})
+			cmd.Lookup("get").Lookup("pod").HelpData()
+		}()
+	}
+	wg.Wait()
+}
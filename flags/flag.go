@@ -2,6 +2,7 @@ package flags
 
 import (
 	"errors"
+	"io/ioutil"
 	"strconv"
 	"strings"
 	"time"
@@ -9,11 +10,15 @@ import (
 
 const (
 	defaultDelimiter = ","
+	filePrefix       = "@"
+	// Redacted is displayed instead of secret flag value.
+	Redacted = "******"
 )
 
 var (
 	ErrMulitipleTimesSet = errors.New("multiple times set")
 	ErrInvalidShortFlag  = errors.New("invalid short flag name")
+	ErrInvalidSecret     = errors.New("invalid value " + Redacted)
 )
 
 type Flag struct {
@@ -26,6 +31,28 @@ type Flag struct {
 	Raw                   string
 	AllowMultipleTimesSet bool
 	Delimiter             string
+	// Default is a text representation of default value. it is empty if value is zero or secret.
+	Default string
+	// FromFile allows value to be read from a file by "@path".
+	FromFile bool
+	// EnvVar is environment variable name used when flag is not set.
+	EnvVar string
+	// Secret flag value is never displayed.
+	Secret bool
+	// Prompt is displayed to ask value interactively when flag is not set.
+	Prompt string
+	// Category is a title of section in which flag is displayed in help.
+	Category string
+	// Hidden flag is not displayed in help and completions.
+	Hidden bool
+	// Deprecated is a message displayed when flag is used.
+	Deprecated string
+	// DeprecatedAliases maps deprecated alias to its message. they work as Aliases.
+	DeprecatedAliases map[string]string
+	// ReplacedBy is a name of flag which receives the value instead of this flag.
+	ReplacedBy string
+	// ResetVar restores Var to its default value. it is called by Reset.
+	ResetVar func()
 }
 
 func (f Flag) HasName(name string) bool {
@@ -37,7 +64,33 @@ func (f Flag) HasName(name string) bool {
 			return true
 		}
 	}
-	return false
+	_, deprecated := f.DeprecatedAliases[name]
+	return deprecated
+}
+
+// names return all non empty names which HasName matches.
+func (f *Flag) names() []string {
+	names := make([]string, 0, 2+len(f.Aliases)+len(f.DeprecatedAliases))
+	for _, name := range append([]string{f.Long, f.Short}, f.Aliases...) {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	for alias := range f.DeprecatedAliases {
+		names = append(names, alias)
+	}
+	return names
+}
+
+// DeprecationMessage return message if flag is used by deprecated name.
+func (f Flag) DeprecationMessage(name string) (string, bool) {
+	if msg, ok := f.DeprecatedAliases[name]; ok {
+		return msg, true
+	}
+	if f.Deprecated != "" {
+		return f.Deprecated, true
+	}
+	return "", false
 }
 
 func (f Flag) Name() string {
@@ -51,16 +104,111 @@ func (f *Flag) Set(s string) error {
 	if f.IsSet && !f.AllowMultipleTimesSet {
 		return ErrMulitipleTimesSet
 	}
+	raw := s
+	if f.FromFile && strings.HasPrefix(s, filePrefix) {
+		v, err := readValueFile(s[len(filePrefix):])
+		if err != nil {
+			return err
+		}
+		s = v
+	}
 	f.IsSet = true
-	f.Raw = s
+	f.Raw = raw
+	return f.set(s)
+}
+
+func (f *Flag) set(s string) error {
+	var err error
 	if multi, ok := f.Var.(MultiVar); ok {
 		delimiter := f.Delimiter
 		if delimiter == "" {
 			delimiter = defaultDelimiter
 		}
-		return multi.SetMulti(s, delimiter)
+		err = multi.SetMulti(s, delimiter)
+	} else {
+		err = f.Var.Set(s)
+	}
+	if err != nil && f.Secret {
+		return ErrInvalidSecret
 	}
-	return f.Var.Set(s)
+	return err
+}
+
+// Reset clears IsSet and Raw, and restores Var by ResetVar so that flag can be set again.
+func (f *Flag) Reset() {
+	f.IsSet = false
+	f.Raw = ""
+	if f.ResetVar != nil {
+		f.ResetVar()
+	}
+}
+
+// RawValue return Raw. if flag is secret, Redacted is returned instead.
+func (f *Flag) RawValue() string {
+	if f.Secret && f.Raw != "" {
+		return Redacted
+	}
+	return f.Raw
+}
+
+// Value return current value of Var as string. multi values are joined by Delimiter.
+// Raw is returned if Var is not a type of this package.
+func (f *Flag) Value() string {
+	delimiter := f.Delimiter
+	if delimiter == "" {
+		delimiter = defaultDelimiter
+	}
+	switch v := f.Var.(type) {
+	case *StringVar:
+		return string(*v)
+	case *IntVar:
+		return strconv.Itoa(int(*v))
+	case *FloatVar:
+		return strconv.FormatFloat(float64(*v), 'g', -1, 64)
+	case *BoolVar:
+		return strconv.FormatBool(bool(*v))
+	case *DurationVar:
+		return time.Duration(*v).String()
+	case *StringsVar:
+		return strings.Join(*v, delimiter)
+	case *IntsVar:
+		ss := make([]string, 0, len(*v))
+		for _, i := range *v {
+			ss = append(ss, strconv.Itoa(i))
+		}
+		return strings.Join(ss, delimiter)
+	default:
+		return f.Raw
+	}
+}
+
+// CheckValue return error if s can not be set to the flag. Var is not changed.
+// values read from a file and Var which is not a type of this package are not checked.
+func (f *Flag) CheckValue(s string) error {
+	if f.FromFile && strings.HasPrefix(s, filePrefix) {
+		return nil
+	}
+	var v Var
+	switch f.Var.(type) {
+	case *StringVar:
+		v = new(StringVar)
+	case *IntVar:
+		v = new(IntVar)
+	case *FloatVar:
+		v = new(FloatVar)
+	case *BoolVar:
+		v = new(BoolVar)
+	case *DurationVar:
+		v = new(DurationVar)
+	case *StringsVar:
+		v = new(StringsVar)
+	case *IntsVar:
+		v = new(IntsVar)
+	default:
+		return nil
+	}
+	tmp := Flag{Var: v, Delimiter: f.Delimiter, Secret: f.Secret}
+	return tmp.set(s)
 }
 
 func (f *Flag) Validate() error {
@@ -70,6 +218,14 @@ func (f *Flag) Validate() error {
 	return nil
 }
 
+// Type return value type name of flag like "int". empty string is returned if Var does not implement TypedVar.
+func (f *Flag) Type() string {
+	if t, ok := f.Var.(TypedVar); ok {
+		return t.Type()
+	}
+	return ""
+}
+
 func (f *Flag) IsBool() bool {
 	_, isBool := f.Var.(BooleanVar)
 	return isBool
@@ -79,6 +235,12 @@ type Var interface {
 	Set(string) error
 }
 
+// TypedVar is a Var which can tell its value type name.
+type TypedVar interface {
+	Var
+	Type() string
+}
+
 type MultiVar interface {
 	Var
 	SetMulti(v string, delimiter string) error
@@ -86,6 +248,8 @@ type MultiVar interface {
 
 type StringVar string
 
+func (*StringVar) Type() string { return "string" }
+
 func (sv *StringVar) Set(s string) error {
 	*sv = StringVar(s)
 	return nil
@@ -93,6 +257,8 @@ func (sv *StringVar) Set(s string) error {
 
 type IntVar int
 
+func (*IntVar) Type() string { return "int" }
+
 func (iv *IntVar) Set(s string) error {
 	n, err := strconv.Atoi(s)
 	if err != nil {
@@ -104,6 +270,8 @@ func (iv *IntVar) Set(s string) error {
 
 type FloatVar float64
 
+func (*FloatVar) Type() string { return "float" }
+
 func (fv *FloatVar) Set(s string) error {
 	f, err := strconv.ParseFloat(s, 64)
 	if err != nil {
@@ -120,6 +288,8 @@ type BooleanVar interface {
 
 type BoolVar bool
 
+func (*BoolVar) Type() string { return "bool" }
+
 func (bv *BoolVar) Set(s string) error {
 	b, err := strconv.ParseBool(s)
 	if err != nil {
@@ -136,6 +306,8 @@ func (bv *BoolVar) SetBool(b bool) error {
 
 type StringsVar []string
 
+func (*StringsVar) Type() string { return "strings" }
+
 func (sv *StringsVar) Set(s string) error {
 	*sv = append(*sv, s)
 	return nil
@@ -156,6 +328,8 @@ func (sv *StringsVar) SetMulti(s, delimiter string) error {
 
 type IntsVar []int
 
+func (*IntsVar) Type() string { return "ints" }
+
 func (iv *IntsVar) Set(s string) error {
 	i, err := strconv.Atoi(s)
 	if err != nil {
@@ -178,8 +352,40 @@ func (iv *IntsVar) SetMulti(s, delimiter string) error {
 	return nil
 }
 
+// FileVar reads the file of given path and set its content to Target.
+type FileVar struct {
+	Target *Flag
+}
+
+func (*FileVar) Type() string { return "path" }
+
+func (fv *FileVar) Set(path string) error {
+	t := fv.Target
+	if t.IsSet && !t.AllowMultipleTimesSet {
+		return ErrMulitipleTimesSet
+	}
+	v, err := readValueFile(path)
+	if err != nil {
+		return err
+	}
+	t.IsSet = true
+	t.Raw = filePrefix + path
+	return t.set(v)
+}
+
+// readValueFile return file content without trailing newlines.
+func readValueFile(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
 type DurationVar time.Duration
 
+func (*DurationVar) Type() string { return "duration" }
+
 func (dv *DurationVar) Set(s string) error {
 	d, err := time.ParseDuration(s)
 	if err != nil {
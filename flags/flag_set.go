@@ -26,34 +26,54 @@ type FlagSet struct {
 	Flags []*Flag
 	*sync.RWMutex
 	once sync.Once
+	// index maps flag names to flags added by Add. it is used while indexed equals to len(Flags).
+	index   map[string]*Flag
+	indexed int
 }
 
 // Add add given flag.
-// if flag Name(Long, Short, Aliases) conflict already exists flags, returns ErrFlagAlreadyExists.
+// if flag Name(Long, Short, Aliases, DeprecatedAliases) conflict already exists flags, returns ErrFlagAlreadyExists.
 func (fs *FlagSet) Add(f *Flag) error {
 	fs.lasyInit()
 	name := f.Name()
 	if name == "" {
 		return ErrFlagNameRequired
 	}
-	found, err := fs.Lookup(name)
-	if found != nil {
-		return ErrFlagAlreadyExists
-	}
-	if err == ErrFlagNotFound {
-		// ok
-	} else if err != nil {
-		return err
+	for _, name := range f.names() {
+		found, err := fs.Lookup(name)
+		if found != nil {
+			return ErrFlagAlreadyExists
+		}
+		if err == ErrFlagNotFound {
+			// ok
+		} else if err != nil {
+			return err
+		}
 	}
 
 	fs.Lock()
 	fs.Flags = append(fs.Flags, f)
+	fs.addIndex(f)
 	fs.Unlock()
 	return nil
 }
 
+// addIndex index f if all flags are indexed. if Flags is modified directly, Lookup falls back to linear search.
+func (fs *FlagSet) addIndex(f *Flag) {
+	if fs.indexed != len(fs.Flags)-1 {
+		return
+	}
+	if fs.index == nil {
+		fs.index = make(map[string]*Flag)
+	}
+	for _, name := range f.names() {
+		fs.index[name] = f
+	}
+	fs.indexed++
+}
+
 // Lookup lookup flag by given flag name. if not found, returns ErrFlagNotFound.
-// Long, Short, Aliases are checked.
+// Long, Short, Aliases and DeprecatedAliases are checked.
 func (fs *FlagSet) Lookup(name string) (*Flag, error) {
 	fs.lasyInit()
 	if name == "" {
@@ -61,6 +81,12 @@ func (fs *FlagSet) Lookup(name string) (*Flag, error) {
 	}
 	fs.RLock()
 	defer fs.RUnlock()
+	if fs.indexed == len(fs.Flags) {
+		// flag may be renamed after Add, so hit is verified and miss falls back to linear search.
+		if f, ok := fs.index[name]; ok && f.HasName(name) {
+			return f, nil
+		}
+	}
 	for _, f := range fs.Flags {
 		if f.HasName(name) {
 			return f, nil
@@ -69,8 +95,24 @@ func (fs *FlagSet) Lookup(name string) (*Flag, error) {
 	return nil, ErrFlagNotFound
 }
 
-func (fs *FlagSet) Traverse(fn func(f *Flag)) {
+// Reset resets all flags.
+func (fs *FlagSet) Reset() {
+	fs.lasyInit()
+	fs.RLock()
+	defer fs.RUnlock()
 	for _, f := range fs.Flags {
+		f.Reset()
+	}
+}
+
+// Traverse call fn with each flag in the order of addition.
+// fn is called without lock, so it can use FlagSet.
+func (fs *FlagSet) Traverse(fn func(f *Flag)) {
+	fs.lasyInit()
+	fs.RLock()
+	snapshot := fs.Flags
+	fs.RUnlock()
+	for _, f := range snapshot {
 		fn(f)
 	}
 }
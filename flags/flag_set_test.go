@@ -25,6 +25,26 @@ func TestFlagSet_Add(t *testing.T) {
 			t.Errorf("adding same name flag should return FlagAlreadyExists error,but got %s", err)
 		}
 	})
+
+	t.Run("short and alias conflict", func(t *testing.T) {
+		fs := &flags.FlagSet{}
+		addFlags(t, fs, &flags.Flag{Long: "label", Short: "l", Aliases: []string{"tag"}})
+		for _, f := range []*flags.Flag{{Long: "level", Short: "l"}, {Long: "name", Aliases: []string{"tag"}}} {
+			if err := fs.Add(f); err != flags.ErrFlagAlreadyExists {
+				t.Errorf("adding %s should return FlagAlreadyExists error, but got %v", f.Long, err)
+			}
+		}
+	})
+
+	t.Run("deprecated alias conflict", func(t *testing.T) {
+		fs := &flags.FlagSet{}
+		addFlags(t, fs, &flags.Flag{Long: "max", DeprecatedAliases: map[string]string{"limit": "use --max"}})
+		for _, f := range []*flags.Flag{{Long: "limit"}, {Long: "size", DeprecatedAliases: map[string]string{"max": "use --size"}}} {
+			if err := fs.Add(f); err != flags.ErrFlagAlreadyExists {
+				t.Errorf("adding %s should return FlagAlreadyExists error, but got %v", f.Long, err)
+			}
+		}
+	})
 }
 
 func TestFlagSet_Lookup(t *testing.T) {
@@ -51,6 +71,24 @@ func TestFlagSet_Lookup(t *testing.T) {
 			t.Errorf("got %v, want %v", got, flagLabel)
 		}
 	})
+	t.Run("renamed", func(t *testing.T) {
+		fs := &flags.FlagSet{}
+		f := &flags.Flag{Long: "label", Aliases: []string{"tag"}}
+		addFlags(t, fs, f)
+		f.Aliases = nil
+		if _, err := fs.Lookup("tag"); err != flags.ErrFlagNotFound {
+			t.Errorf("removed alias should not be found, got %v", err)
+		}
+		f.Long, f.Short = "name", "n"
+		for _, name := range []string{"name", "n"} {
+			if got, err := fs.Lookup(name); got != f {
+				t.Errorf("name %s given after Add should be found, got %v", name, err)
+			}
+		}
+		if _, err := fs.Lookup("label"); err != flags.ErrFlagNotFound {
+			t.Errorf("old name should not be found, got %v", err)
+		}
+	})
 	t.Run("empty name", func(t *testing.T) {
 		if _, err := (&flags.FlagSet{}).Lookup(""); err != flags.ErrFlagNotFound {
 			t.Errorf("want ErrFlagNotFound, got %v", err)
@@ -74,6 +112,17 @@ func TestFlagSet_Traverse(t *testing.T) {
 	}
 }
 
+func TestFlagSet_Reset(t *testing.T) {
+	fs := &flags.FlagSet{}
+	addFlags(t, fs, &flags.Flag{Short: "a", IsSet: true, Raw: "x"}, &flags.Flag{Short: "b", IsSet: true})
+	fs.Reset()
+	fs.Traverse(func(f *flags.Flag) {
+		if f.IsSet || f.Raw != "" {
+			t.Errorf("%s is not reset", f.Name())
+		}
+	})
+}
+
 func TestParseError_Error(t *testing.T) {
 	err := &flags.ParseError{FlagName: "label", Msg: "err message"}
 	got, want := err.Error(), "flag label err message"
@@ -1,6 +1,8 @@
 package flags_test
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
@@ -24,6 +26,23 @@ func TestFlag_HasName(t *testing.T) {
 	})
 }
 
+func TestFlag_DeprecationMessage(t *testing.T) {
+	f := &flags.Flag{Long: "max", Aliases: []string{"upper"}, DeprecatedAliases: map[string]string{"limit": "use --max"}}
+	if !f.HasName("limit") {
+		t.Error("deprecated alias should work as alias")
+	}
+	if msg, ok := f.DeprecationMessage("limit"); !ok || msg != "use --max" {
+		t.Errorf("got %q, %v", msg, ok)
+	}
+	if _, ok := f.DeprecationMessage("upper"); ok {
+		t.Error("alias is not deprecated")
+	}
+	f.Deprecated = "will be removed"
+	if msg, ok := f.DeprecationMessage("max"); !ok || msg != "will be removed" {
+		t.Errorf("got %q, %v", msg, ok)
+	}
+}
+
 func TestFlag_Name(t *testing.T) {
 	t.Run("long name take precedence over short name", func(t *testing.T) {
 		f := &flags.Flag{Long: "label", Short: "l"}
@@ -69,6 +88,197 @@ func TestFlag_Set(t *testing.T) {
 	})
 }
 
+func TestFlag_Set_fromFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "flag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("secret\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	t.Run("read from file", func(t *testing.T) {
+		var token string
+		flag := &flags.Flag{Long: "token", Var: (*flags.StringVar)(&token), FromFile: true}
+		if err := flag.Set("@" + f.Name()); err != nil {
+			t.Fatalf("Flag.Set() %v", err)
+		}
+		if token != "secret" {
+			t.Errorf("got %q, want %q", token, "secret")
+		}
+		if flag.Raw != "@"+f.Name() {
+			t.Errorf("Flag.Raw should keep given value, got %q", flag.Raw)
+		}
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		var token string
+		flag := &flags.Flag{Long: "token", Var: (*flags.StringVar)(&token)}
+		if err := flag.Set("@" + f.Name()); err != nil {
+			t.Fatalf("Flag.Set() %v", err)
+		}
+		if token != "@"+f.Name() {
+			t.Errorf("got %q, want literal value", token)
+		}
+	})
+
+	t.Run("file not exists", func(t *testing.T) {
+		var token string
+		flag := &flags.Flag{Long: "token", Var: (*flags.StringVar)(&token), FromFile: true}
+		if err := flag.Set("@" + f.Name() + ".none"); err == nil {
+			t.Error("want error, but no error")
+		}
+		if flag.IsSet || flag.Raw != "" {
+			t.Errorf("flag should not be set, got IsSet=%v Raw=%q", flag.IsSet, flag.Raw)
+		}
+	})
+}
+
+func TestFileVar_Set(t *testing.T) {
+	f, err := ioutil.TempFile("", "flag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("100\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	var n int
+	target := &flags.Flag{Long: "num", Var: (*flags.IntVar)(&n), FromFile: true}
+	fv := &flags.FileVar{Target: target}
+	if err := fv.Set(f.Name()); err != nil {
+		t.Fatalf("FileVar.Set() %v", err)
+	}
+	if n != 100 {
+		t.Errorf("got %d, want 100", n)
+	}
+	if !target.IsSet {
+		t.Error("target flag should be recorded as set")
+	}
+	if err := fv.Set(f.Name()); err != flags.ErrMulitipleTimesSet {
+		t.Errorf("want ErrMulitipleTimesSet, got %v", err)
+	}
+}
+
+func TestFlag_Set_secret(t *testing.T) {
+	var n int
+	f := &flags.Flag{Long: "pin", Var: (*flags.IntVar)(&n), Secret: true}
+	err := f.Set("abc123")
+	if err != flags.ErrInvalidSecret {
+		t.Fatalf("want ErrInvalidSecret, got %v", err)
+	}
+	if got := f.RawValue(); got != flags.Redacted {
+		t.Errorf("secret raw value should be redacted, got %q", got)
+	}
+}
+
+func TestFlag_RawValue(t *testing.T) {
+	var s string
+	f := &flags.Flag{Long: "label", Var: (*flags.StringVar)(&s)}
+	if got := f.RawValue(); got != "" {
+		t.Errorf("got %q, want empty", got)
+	}
+	if err := f.Set("app"); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.RawValue(); got != "app" {
+		t.Errorf("got %q, want %q", got, "app")
+	}
+}
+
+func TestFlag_Value(t *testing.T) {
+	s, i, fl, b, d := "app", 3, 1.5, true, time.Second
+	ss, is := []string{"a", "b"}, []int{1, 2}
+	tests := map[string]struct {
+		flag *flags.Flag
+		want string
+	}{
+		"string":   {flag: &flags.Flag{Var: (*flags.StringVar)(&s)}, want: "app"},
+		"int":      {flag: &flags.Flag{Var: (*flags.IntVar)(&i)}, want: "3"},
+		"float":    {flag: &flags.Flag{Var: (*flags.FloatVar)(&fl)}, want: "1.5"},
+		"bool":     {flag: &flags.Flag{Var: (*flags.BoolVar)(&b)}, want: "true"},
+		"duration": {flag: &flags.Flag{Var: (*flags.DurationVar)(&d)}, want: "1s"},
+		"strings":  {flag: &flags.Flag{Var: (*flags.StringsVar)(&ss)}, want: "a,b"},
+		"ints":     {flag: &flags.Flag{Var: (*flags.IntsVar)(&is), Delimiter: ":"}, want: "1:2"},
+		"other":    {flag: &flags.Flag{Var: &flags.FileVar{}, Raw: "path"}, want: "path"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.flag.Value(); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFlag_CheckValue(t *testing.T) {
+	i, is := 3, []int{1}
+	tests := map[string]struct {
+		flag    *flags.Flag
+		value   string
+		wantErr bool
+	}{
+		"valid":     {flag: &flags.Flag{Var: (*flags.IntVar)(&i)}, value: "10"},
+		"invalid":   {flag: &flags.Flag{Var: (*flags.IntVar)(&i)}, value: "abc", wantErr: true},
+		"multi":     {flag: &flags.Flag{Var: (*flags.IntsVar)(&is), Delimiter: ":"}, value: "2:x", wantErr: true},
+		"from file": {flag: &flags.Flag{Var: (*flags.IntVar)(&i), FromFile: true}, value: "@not_exists"},
+		"secret":    {flag: &flags.Flag{Var: (*flags.IntVar)(&i), Secret: true}, value: "abc", wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.flag.CheckValue(tc.value); (err != nil) != tc.wantErr {
+				t.Errorf("CheckValue(%q) %v", tc.value, err)
+			}
+			if i != 3 || len(is) != 1 || tc.flag.IsSet {
+				t.Errorf("flag should not be changed, got %d %v", i, is)
+			}
+		})
+	}
+}
+
+func TestFlag_Reset(t *testing.T) {
+	var s string
+	f := &flags.Flag{Long: "label", Var: (*flags.StringVar)(&s)}
+	if err := f.Set("app"); err != nil {
+		t.Fatal(err)
+	}
+	f.Reset()
+	if f.IsSet || f.Raw != "" {
+		t.Errorf("got IsSet=%v Raw=%q", f.IsSet, f.Raw)
+	}
+	if err := f.Set("ops"); err != nil {
+		t.Errorf("flag should be set again after Reset, got %v", err)
+	}
+
+	t.Run("multi value", func(t *testing.T) {
+		ss := []string{"default"}
+		f := &flags.Flag{
+			Long:                  "outs",
+			Var:                   (*flags.StringsVar)(&ss),
+			AllowMultipleTimesSet: true,
+			ResetVar:              func() { ss = []string{"default"} },
+		}
+		for i := 0; i < 2; i++ {
+			for _, v := range []string{"a", "b"} {
+				if err := f.Set(v); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(ss, []string{"default", "a", "b"}); diff != "" {
+				t.Errorf("given values should be appended to default (-got +want)%s", diff)
+			}
+			f.Reset()
+			if diff := cmp.Diff(ss, []string{"default"}); diff != "" {
+				t.Errorf("Reset should restore default (-got +want)%s", diff)
+			}
+		}
+	})
+}
+
 func TestFlag_Validate(t *testing.T) {
 	tests := map[string]struct {
 		flag *flags.Flag
@@ -102,6 +312,20 @@ func TestFlag_IsBool(t *testing.T) {
 	}
 }
 
+func TestFlag_Type(t *testing.T) {
+	var n int
+	if got := (&flags.Flag{Var: (*flags.IntVar)(&n)}).Type(); got != "int" {
+		t.Errorf("got %q, want int", got)
+	}
+	if got := (&flags.Flag{Var: untyped{}}).Type(); got != "" {
+		t.Errorf("got %q, want empty", got)
+	}
+}
+
+type untyped struct{}
+
+func (untyped) Set(string) error { return nil }
+
 func TestStringVar_Set(t *testing.T) {
 	t.Run("simple", func(t *testing.T) {
 		var s string
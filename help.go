@@ -5,76 +5,426 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/ymgyt/cli/flags"
+	"github.com/ymgyt/cli/internal/term"
 )
 
+// HelpTemplate is a text/template used by HelpFunc. it is rendered with *HelpData.
+// it can be overridden globally by this variable, or per command by Command.HelpTemplate.
+//
+// in addition to text/template builtins, following functions are available.
+//
+//	pad width s     : left justify s to width
+//	padLeft width s : right justify s to width
+//	indent n s      : indent each line of s by n spaces
+//	hang n s        : indent each line of s except the first one by n spaces
+//	wrap width s    : wrap s to lines shorter than width
+//	join sep ss     : strings.Join
+//	add a b         : a + b
+//	sub a b         : a - b
+var HelpTemplate = defaultHelpTemplate // nolint: gochecknoglobals
+
+const defaultHelpTemplate = `{{with or .LongDesc .ShortDesc}}{{wrap $.Width .}}
+
+{{end}}Usage:
+  {{.Usage}}
+{{with .Aliases}}
+Aliases:
+  {{join ", " .}}
+{{end}}{{with .Examples}}
+Examples
+{{range .}}{{with .Description}}  # {{.}}
+{{end}}  {{.Command}}
+{{end}}{{end}}{{range .CommandGroups}}{{$col := add $.CommandWidth 5}}
+{{.Title}}
+{{range .Commands}}  {{if .ShortDesc}}{{pad $.CommandWidth .Name}}   {{hang $col (wrap (sub $.Width $col) .ShortDesc)}}{{else}}{{.Name}}{{end}}
+{{end}}{{end}}{{range .FlagGroups}}{{$col := add $.FlagWidth 5}}
+{{.Title}}
+{{range .Flags}}  {{if .Usage}}{{pad $.FlagWidth .Names}}   {{hang $col (wrap (sub $.Width $col) .Usage)}}{{else}}{{.Names}}{{end}}
+{{end}}{{end}}`
+
+const (
+	defaultHelpWidth = 80
+	// otherGroupTitle is the title of the section for ungrouped commands and flags.
+	otherGroupTitle = "Other"
+)
+
+// HelpData is the data model of help template.
+type HelpData struct {
+	// Path is a command names from root joined by space.
+	Path        string
+	Usage       string
+	ShortDesc   string
+	LongDesc    string
+	Aliases     []string
+	Examples    []*Example
+	SubCommands []*HelpCommand
+	// CommandGroups contains SubCommands grouped by Command.Groups.
+	// if no groups are defined, a "SubCommands" group contains all. user aliases and plugins follow them as "Aliases" and "Plugins" groups.
+	CommandGroups []*HelpCommandGroup
+	// FlagGroups contains flags grouped by category, then inherited "Global Options" if exists.
+	// if no categories are defined, an "Options" group contains all own flags.
+	FlagGroups []*HelpFlagGroup
+	// CommandWidth is the length of the longest sub command name.
+	CommandWidth int
+	// FlagWidth is the length of the longest HelpFlag.Names.
+	FlagWidth int
+	// Width is the column size of output. terminal width is used if available.
+	Width int
+}
+
+type HelpCommandGroup struct {
+	Title    string
+	Commands []*HelpCommand
+}
+
+type HelpCommand struct {
+	Name      string
+	Aliases   []string
+	ShortDesc string
+	GroupID   string
+}
+
+type HelpFlagGroup struct {
+	Title string
+	Flags []*HelpFlag
+}
+
+type HelpFlag struct {
+	Long        string
+	Short       string
+	Aliases     []string
+	Type        string
+	Default     string
+	EnvVar      string
+	Description string
+	Category    string
+	Deprecated  string
+	// Names is formatted flag names with value placeholder like "-m, --max, --limit int".
+	Names string
+	// Usage is description with default value, environment variable and deprecation message.
+	Usage string
+}
+
 // HlepFunc print help message to given writer
 func HelpFunc(w io.Writer, c *Command) {
+	tmpl, err := template.New("help").Funcs(helpFuncs()).Parse(c.helpTemplate())
+	if err != nil {
+		fmt.Fprintf(w, "help template error: %s\n", err)
+		return
+	}
+	data := c.HelpData()
+	if width, ok := term.Width(w); ok {
+		data.Width = width
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		fmt.Fprintf(w, "help template error: %s\n", err)
+	}
+}
 
-	var longestSubcmd string
-	sortCmds := func(subs []*Command) []string {
-		var names = make([]string, 0, len(subs))
-		for _, sub := range subs {
-			if len(sub.Name) > len(longestSubcmd) {
-				longestSubcmd = sub.Name
-			}
-			names = append(names, sub.Name)
+func (c *Command) helpTemplate() string {
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if cmd.HelpTemplate != "" {
+			return cmd.HelpTemplate
 		}
-		sort.Strings(names)
-		return names
+	}
+	return HelpTemplate
+}
+
+// HelpData build data passed to help template.
+func (c *Command) HelpData() *HelpData {
+	c.lasyInit()
+	data := &HelpData{
+		Path:      c.Path(),
+		ShortDesc: c.ShortDesc,
+		LongDesc:  c.LongDesc,
+		Examples:  c.Examples,
+		Width:     defaultHelpWidth,
+	}
+	if len(c.Aliases) > 0 {
+		data.Aliases = append([]string{c.Name}, c.Aliases...)
 	}
 
-	var b strings.Builder
-	b.WriteString(c.LongDesc + "\n")
-
-	sorted := sortCmds(c.SubCommands)
-	indent := "  "
-	if len(sorted) > 0 {
-		indent := "  "
-		b.WriteString("\nSubCommands")
-		for _, subName := range sorted {
-			sub := c.Lookup(subName)
-			b.WriteString("\n" + indent + fmt.Sprintf("%*s: %s", len(longestSubcmd), sub.Name, sub.ShortDesc))
+	for _, sub := range c.SubCommands {
+		if sub.Hidden {
+			continue
 		}
-		b.WriteString("\n")
-	} else {
-		var longestFlag string
-		var fs []*flags.Flag
-		c.flagSet.Traverse(func(f *flags.Flag) {
-			if len(f.Long) > len(longestFlag) {
-				longestFlag = f.Long
-			}
-			fs = append(fs, f)
+		if len(sub.Name) > data.CommandWidth {
+			data.CommandWidth = len(sub.Name)
+		}
+		data.SubCommands = append(data.SubCommands, &HelpCommand{Name: sub.Name, Aliases: sub.Aliases, ShortDesc: sub.ShortDesc, GroupID: sub.GroupID})
+	}
+	if !c.DisableSort {
+		sort.SliceStable(data.SubCommands, func(i, j int) bool {
+			return data.SubCommands[i].Name < data.SubCommands[j].Name
 		})
-		sort.Slice(fs, func(i, j int) bool {
+	}
+	data.CommandGroups = c.helpCommandGroups(data.SubCommands)
+	if names := c.userAliasNames(); len(names) > 0 {
+		group := &HelpCommandGroup{Title: "Aliases"}
+		for _, name := range names {
+			if len(name) > data.CommandWidth {
+				data.CommandWidth = len(name)
+			}
+			group.Commands = append(group.Commands, &HelpCommand{Name: name, ShortDesc: c.UserAliases[name]})
+		}
+		data.CommandGroups = append(data.CommandGroups, group)
+	}
+	if plugins := c.ListPlugins(); len(plugins) > 0 {
+		group := &HelpCommandGroup{Title: "Plugins"}
+		for _, p := range plugins {
+			if len(p.Name) > data.CommandWidth {
+				data.CommandWidth = len(p.Name)
+			}
+			group.Commands = append(group.Commands, &HelpCommand{Name: p.Name, ShortDesc: p.Path})
+		}
+		data.CommandGroups = append(data.CommandGroups, group)
+	}
+
+	local := visibleFlags(c.flagSet, c.persistentFlagSet)
+	var inherited []*flags.Flag
+	for p := c.parent; p != nil; p = p.parent {
+		inherited = append(inherited, visibleFlags(p.persistentFlagSet)...)
+	}
+	groups := groupFlags(local, !c.DisableSort)
+	if len(inherited) > 0 {
+		groups = append(groups, newHelpFlagGroup("Global Options", sortFlags(inherited, !c.DisableSort)))
+	}
+	for _, group := range groups {
+		for _, f := range group.Flags {
+			if len(f.Names) > data.FlagWidth {
+				data.FlagWidth = len(f.Names)
+			}
+		}
+		data.FlagGroups = append(data.FlagGroups, group)
+	}
+
+	data.Usage = data.Path
+	if len(data.FlagGroups) > 0 {
+		data.Usage += " [flags]"
+	}
+	if len(data.SubCommands) > 0 {
+		data.Usage += " <command>"
+	}
+
+	return data
+}
+
+// helpCommandGroups group commands in the order of c.Groups. undefined group follows them, and ungrouped ones are last.
+func (c *Command) helpCommandGroups(cmds []*HelpCommand) []*HelpCommandGroup {
+	if len(cmds) == 0 {
+		return nil
+	}
+	grouped := false
+	for _, cmd := range cmds {
+		if cmd.GroupID != "" {
+			grouped = true
+		}
+	}
+	if !grouped {
+		return []*HelpCommandGroup{{Title: "SubCommands", Commands: cmds}}
+	}
+
+	var groups []*HelpCommandGroup
+	byID := make(map[string]*HelpCommandGroup)
+	for _, g := range c.Groups {
+		group := &HelpCommandGroup{Title: g.Title}
+		byID[g.ID] = group
+		groups = append(groups, group)
+	}
+	other := &HelpCommandGroup{Title: otherGroupTitle}
+	for _, cmd := range cmds {
+		if cmd.GroupID == "" {
+			other.Commands = append(other.Commands, cmd)
+			continue
+		}
+		group, ok := byID[cmd.GroupID]
+		if !ok {
+			group = &HelpCommandGroup{Title: cmd.GroupID}
+			byID[cmd.GroupID] = group
+			groups = append(groups, group)
+		}
+		group.Commands = append(group.Commands, cmd)
+	}
+	groups = append(groups, other)
+
+	nonEmpty := groups[:0]
+	for _, group := range groups {
+		if len(group.Commands) > 0 {
+			nonEmpty = append(nonEmpty, group)
+		}
+	}
+	return nonEmpty
+}
+
+// groupFlags group flags by category in the order of definition. uncategorized ones are last.
+func groupFlags(fs []*flags.Flag, sorted bool) []*HelpFlagGroup {
+	if len(fs) == 0 {
+		return nil
+	}
+	var categories []string
+	byCategory := make(map[string][]*flags.Flag)
+	for _, f := range fs {
+		if _, ok := byCategory[f.Category]; !ok && f.Category != "" {
+			categories = append(categories, f.Category)
+		}
+		byCategory[f.Category] = append(byCategory[f.Category], f)
+	}
+	if len(categories) == 0 {
+		return []*HelpFlagGroup{newHelpFlagGroup("Options", sortFlags(fs, sorted))}
+	}
+
+	var groups []*HelpFlagGroup
+	for _, category := range categories {
+		groups = append(groups, newHelpFlagGroup(category, sortFlags(byCategory[category], sorted)))
+	}
+	if other := byCategory[""]; len(other) > 0 {
+		groups = append(groups, newHelpFlagGroup(otherGroupTitle, sortFlags(other, sorted)))
+	}
+	return groups
+}
+
+func sortFlags(fs []*flags.Flag, sorted bool) []*flags.Flag {
+	if sorted {
+		sort.SliceStable(fs, func(i, j int) bool {
 			return fs[i].Name() < fs[j].Name()
 		})
+	}
+	return fs
+}
 
-		for i, f := range fs {
-			if i == 0 {
-				b.WriteString("\nOptions")
-			}
-			long := f.Long
-			if long == "" {
-				long = strings.Repeat(" ", len(longestFlag)+2) // for minus minus
-			} else {
-				long = fmt.Sprintf("--%-*s", len(longestFlag), f.Long)
+func visibleFlags(fss ...*flags.FlagSet) []*flags.Flag {
+	var fs []*flags.Flag
+	for _, flagSet := range fss {
+		if flagSet == nil {
+			continue
+		}
+		flagSet.Traverse(func(f *flags.Flag) {
+			if !f.Hidden {
+				fs = append(fs, f)
 			}
-			short := f.Short
-			if short == "" {
-				short = "   " // space for minus char ,
-			} else {
-				delimiter := ","
-				if f.Long == "" {
-					delimiter = " "
+		})
+	}
+	return fs
+}
+
+func newHelpFlagGroup(title string, fs []*flags.Flag) *HelpFlagGroup {
+	group := &HelpFlagGroup{Title: title}
+	for _, f := range fs {
+		group.Flags = append(group.Flags, newHelpFlag(f))
+	}
+	return group
+}
+
+func newHelpFlag(f *flags.Flag) *HelpFlag {
+	hf := &HelpFlag{
+		Long:        f.Long,
+		Short:       f.Short,
+		Aliases:     f.Aliases,
+		Type:        f.Type(),
+		Default:     f.Default,
+		EnvVar:      f.EnvVar,
+		Description: f.Description,
+		Category:    f.Category,
+		Deprecated:  f.Deprecated,
+	}
+	if f.Secret {
+		hf.Default = ""
+	}
+
+	var names []string
+	if f.Long != "" {
+		names = append(names, "--"+f.Long)
+	}
+	for _, alias := range f.Aliases {
+		names = append(names, flagDisplayName(alias))
+	}
+	if f.Short != "" {
+		names = append([]string{"-" + f.Short}, names...)
+		hf.Names = strings.Join(names, ", ")
+	} else {
+		// align with the flags which have short name
+		hf.Names = "    " + strings.Join(names, ", ")
+	}
+	if !f.IsBool() && hf.Type != "" {
+		hf.Names += " " + hf.Type
+	}
+
+	var usage []string
+	if hf.Description != "" {
+		usage = append(usage, hf.Description)
+	}
+	if hf.Default != "" && !f.IsBool() {
+		usage = append(usage, fmt.Sprintf("(default %s)", hf.Default))
+	}
+	if hf.EnvVar != "" {
+		usage = append(usage, fmt.Sprintf("[$%s]", hf.EnvVar))
+	}
+	if hf.Deprecated != "" {
+		usage = append(usage, fmt.Sprintf("(deprecated: %s)", hf.Deprecated))
+	}
+	hf.Usage = strings.Join(usage, " ")
+	return hf
+}
+
+func helpFuncs() template.FuncMap {
+	return template.FuncMap{
+		"pad":     func(width int, s string) string { return fmt.Sprintf("%-*s", width, s) },
+		"padLeft": func(width int, s string) string { return fmt.Sprintf("%*s", width, s) },
+		"indent":  indent,
+		"hang":    hang,
+		"wrap":    wrap,
+		"join":    func(sep string, ss []string) string { return strings.Join(ss, sep) },
+		"add":     func(a, b int) int { return a + b },
+		"sub":     func(a, b int) int { return a - b },
+	}
+}
+
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func hang(n int, s string) string {
+	i := strings.Index(s, "\n")
+	if i < 0 {
+		return s
+	}
+	return s[:i+1] + indent(n, s[i+1:])
+}
+
+// wrap break s into lines shorter than width at spaces. existing newlines are kept.
+func wrap(width int, s string) string {
+	if width <= 0 {
+		return s
+	}
+	var b strings.Builder
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		n := 0
+		for j, word := range strings.Fields(line) {
+			if j > 0 {
+				if n+1+len(word) > width {
+					b.WriteString("\n")
+					n = 0
+				} else {
+					b.WriteString(" ")
+					n++
 				}
-				short = "-" + short + delimiter
 			}
-			b.WriteString("\n" + indent + fmt.Sprintf("%s %s: %s", short, long, f.Description))
+			b.WriteString(word)
+			n += len(word)
 		}
-		b.WriteString("\n")
 	}
-
-	fmt.Fprint(w, b.String())
+	return b.String()
 }
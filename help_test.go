@@ -0,0 +1,232 @@
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli"
+)
+
+func TestHelpFunc_template(t *testing.T) {
+	t.Run("per command", func(t *testing.T) {
+		cmd := buildCmd(nil, nil, nil)
+		cmd.HelpTemplate = `{{.Path}}:{{range .SubCommands}} {{.Name}}{{end}}`
+		var b strings.Builder
+		cli.HelpFunc(&b, cmd)
+		if diff := cmp.Diff(b.String(), "clictl: get version"); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+
+		b.Reset()
+		cli.HelpFunc(&b, cmd.Lookup("get"))
+		if diff := cmp.Diff(b.String(), "clictl get: pod replicaset"); diff != "" {
+			t.Errorf("sub command should inherit parent template. (-got +want)%s", diff)
+		}
+	})
+
+	t.Run("global", func(t *testing.T) {
+		org := cli.HelpTemplate
+		defer func() { cli.HelpTemplate = org }()
+		cli.HelpTemplate = `{{range .FlagGroups}}{{range .Flags}}{{.Long}}:{{.Type}}:{{.Default}} {{end}}{{end}}`
+
+		var b strings.Builder
+		cli.HelpFunc(&b, buildCmd(nil, nil, nil).Lookup("get").Lookup("pod"))
+		if diff := cmp.Diff(b.String(), "help:bool: level:int:1 nums:ints: :bool: "); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+	})
+
+	t.Run("funcs", func(t *testing.T) {
+		cmd := &cli.Command{
+			Name:         "app",
+			LongDesc:     "aaa bbb ccc ddd",
+			HelpTemplate: `{{indent 2 (wrap 8 .LongDesc)}}|{{pad 4 "ab"}}|{{padLeft 4 "ab"}}|{{add 1 2}}`,
+		}
+		var b strings.Builder
+		cli.HelpFunc(&b, cmd)
+		want := "  aaa bbb\n  ccc ddd|ab  |  ab|3"
+		if diff := cmp.Diff(b.String(), want); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+	})
+
+	t.Run("template error", func(t *testing.T) {
+		cmd := &cli.Command{Name: "app", HelpTemplate: `{{.Unknown}}`}
+		var b strings.Builder
+		cli.HelpFunc(&b, cmd)
+		if !strings.Contains(b.String(), "help template error") {
+			t.Errorf("want template error, got %q", b.String())
+		}
+	})
+}
+
+func TestHelpFunc_sections(t *testing.T) {
+	var verbose, force, trace bool
+	var output string
+	var max, size int
+	root := &cli.Command{Name: "app", ShortDesc: "app is an example application which has a long description to be wrapped at the width of terminal"}
+	root.PersistentOptions().
+		Add(&cli.BoolOpt{Var: &verbose, Long: "verbose", Short: "v", Description: "verbose output"}).
+		Add(&cli.StringOpt{Var: &output, Long: "output", Short: "o", Default: "text", EnvVar: "APP_OUTPUT", Description: "output format"})
+	sub := &cli.Command{Name: "sub", Aliases: []string{"s"}, ShortDesc: "sub command"}
+	sub.Options().
+		Add(&cli.BoolOpt{Var: &force, Long: "force", Description: "force to do something even if it is dangerous. this description is wrapped to terminal width"}).
+		Add(&cli.IntOpt{Var: &max, Long: "max", Aliases: []string{"limit"}, Default: 10, Description: "maximum"}).
+		Add(&cli.IntOpt{Var: &size, Long: "size", Description: "size", Deprecated: "use --max instead"}).
+		Add(&cli.BoolOpt{Var: &trace, Long: "trace", Description: "trace", Hidden: true})
+	root.AddCommand(sub.AddCommand(&cli.Command{Name: "subsub"})).
+		AddCommand(&cli.Command{Name: "debug", ShortDesc: "internal debugging", Hidden: true})
+
+	tests := map[string]struct {
+		cmd  *cli.Command
+		want string
+	}{
+		"root": {
+			cmd: root,
+			want: `app is an example application which has a long description to be wrapped at the
+width of terminal
+
+Usage:
+  app [flags] <command>
+
+SubCommands
+  sub   sub command
+
+Options
+  -o, --output string   output format (default text) [$APP_OUTPUT]
+  -v, --verbose         verbose output
+`,
+		},
+		"sub": {
+			cmd: sub,
+			want: `sub command
+
+Usage:
+  app sub [flags] <command>
+
+Aliases:
+  sub, s
+
+SubCommands
+  subsub
+
+Options
+      --force              force to do something even if it is dangerous. this
+                           description is wrapped to terminal width
+      --max, --limit int   maximum (default 10)
+      --size int           size (deprecated: use --max instead)
+
+Global Options
+  -o, --output string      output format (default text) [$APP_OUTPUT]
+  -v, --verbose            verbose output
+`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			cli.HelpFunc(&b, tc.cmd)
+			if diff := cmp.Diff(b.String(), tc.want); diff != "" {
+				t.Errorf("(-got +want)%s", diff)
+			}
+		})
+	}
+}
+
+func TestHelpFunc_groups(t *testing.T) {
+	build := func(disableSort bool) *cli.Command {
+		var a, b, c, d bool
+		root := &cli.Command{
+			Name: "app",
+			Groups: []*cli.Group{
+				{ID: "mgmt", Title: "Management"},
+				{ID: "debug", Title: "Debugging"},
+			},
+			DisableSort: disableSort,
+		}
+		root.Options().
+			Add(&cli.BoolOpt{Var: &d, Long: "dump", Category: "Debugging"}).
+			Add(&cli.BoolOpt{Var: &c, Long: "color"}).
+			Add(&cli.BoolOpt{Var: &b, Long: "trace", Category: "Debugging"}).
+			Add(&cli.BoolOpt{Var: &a, Long: "config", Category: "Configuration"})
+		return root.
+			AddCommand(&cli.Command{Name: "logs", GroupID: "debug"}).
+			AddCommand(&cli.Command{Name: "delete", GroupID: "mgmt"}).
+			AddCommand(&cli.Command{Name: "create", GroupID: "mgmt"}).
+			AddCommand(&cli.Command{Name: "version"}).
+			AddCommand(&cli.Command{Name: "attach", GroupID: "debug"})
+	}
+
+	tests := map[string]struct {
+		cmd  *cli.Command
+		want string
+	}{
+		"sorted": {
+			cmd: build(false),
+			want: `Usage:
+  app [flags] <command>
+
+Management
+  create
+  delete
+
+Debugging
+  attach
+  logs
+
+Other
+  version
+
+Debugging
+      --dump
+      --trace
+
+Configuration
+      --config
+
+Other
+      --color
+`,
+		},
+		"disable sort": {
+			cmd: build(true),
+			want: `Usage:
+  app [flags] <command>
+
+Management
+  delete
+  create
+
+Debugging
+  logs
+  attach
+
+Other
+  version
+
+Debugging
+      --dump
+      --trace
+
+Configuration
+      --config
+
+Other
+      --color
+`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			cli.HelpFunc(&b, tc.cmd)
+			if diff := cmp.Diff(b.String(), tc.want); diff != "" {
+				t.Errorf("(-got +want)%s", diff)
+			}
+		})
+	}
+}
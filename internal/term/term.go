@@ -0,0 +1,45 @@
+// Package term provides minimal terminal handling used by cli packages.
+package term
+
+import (
+	"io"
+	"os"
+)
+
+// IsTerminal reports whether given reader or writer is connected to a terminal.
+func IsTerminal(v interface{}) bool {
+	f, ok := v.(*os.File)
+	if !ok {
+		return false
+	}
+	return isTerminal(f.Fd())
+}
+
+// DisableEcho turns off echo of the terminal connected to r.
+// returned function restores the original state.
+func DisableEcho(r io.Reader) (restore func() error, err error) {
+	f, ok := r.(*os.File)
+	if !ok {
+		return nil, ErrNotTerminal
+	}
+	return disableEcho(f.Fd())
+}
+
+// Width return column size of the terminal connected to w.
+func Width(w interface{}) (int, bool) {
+	f, ok := w.(*os.File)
+	if !ok {
+		return 0, false
+	}
+	return width(f.Fd())
+}
+
+// MakeRaw turns off line buffering, echo and signal keys of the terminal connected to r
+// so that input can be read byte by byte. returned function restores the original state.
+func MakeRaw(r io.Reader) (restore func() error, err error) {
+	f, ok := r.(*os.File)
+	if !ok {
+		return nil, ErrNotTerminal
+	}
+	return makeRaw(f.Fd())
+}
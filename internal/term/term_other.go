@@ -0,0 +1,17 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package term
+
+import "errors"
+
+// ErrNotTerminal is returned when operation requires terminal.
+var ErrNotTerminal = errors.New("not a terminal")
+
+func isTerminal(_ uintptr) bool { return false }
+
+func disableEcho(_ uintptr) (func() error, error) { return nil, ErrNotTerminal }
+
+func makeRaw(_ uintptr) (func() error, error) { return nil, ErrNotTerminal }
+
+func width(_ uintptr) (int, bool) { return 0, false }
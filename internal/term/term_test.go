@@ -0,0 +1,43 @@
+package term_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ymgyt/cli/internal/term"
+)
+
+func TestIsTerminal(t *testing.T) {
+	if term.IsTerminal(&bytes.Buffer{}) {
+		t.Error("bytes.Buffer is not a terminal")
+	}
+	f, err := ioutil.TempFile("", "term")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if term.IsTerminal(f) {
+		t.Error("regular file is not a terminal")
+	}
+}
+
+func TestDisableEcho(t *testing.T) {
+	if _, err := term.DisableEcho(&bytes.Buffer{}); err != term.ErrNotTerminal {
+		t.Errorf("want ErrNotTerminal, got %v", err)
+	}
+}
+
+func TestMakeRaw(t *testing.T) {
+	if _, err := term.MakeRaw(&bytes.Buffer{}); err != term.ErrNotTerminal {
+		t.Errorf("want ErrNotTerminal, got %v", err)
+	}
+}
+
+func TestWidth(t *testing.T) {
+	if _, ok := term.Width(&bytes.Buffer{}); ok {
+		t.Error("bytes.Buffer has no width")
+	}
+}
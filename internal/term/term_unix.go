@@ -0,0 +1,77 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package term
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+// ErrNotTerminal is returned when operation requires terminal.
+var ErrNotTerminal = errors.New("not a terminal")
+
+func getTermios(fd uintptr) (*syscall.Termios, error) {
+	t := &syscall.Termios{}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlGetTermios, uintptr(unsafe.Pointer(t))); errno != 0 {
+		return nil, errno
+	}
+	return t, nil
+}
+
+func setTermios(fd uintptr, t *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlSetTermios, uintptr(unsafe.Pointer(t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func isTerminal(fd uintptr) bool {
+	_, err := getTermios(fd)
+	return err == nil
+}
+
+func disableEcho(fd uintptr) (func() error, error) {
+	org, err := getTermios(fd)
+	if err != nil {
+		return nil, ErrNotTerminal
+	}
+	t := *org
+	t.Lflag &^= syscall.ECHO
+	t.Lflag |= syscall.ICANON | syscall.ISIG
+	if err := setTermios(fd, &t); err != nil {
+		return nil, err
+	}
+	return func() error { return setTermios(fd, org) }, nil
+}
+
+func makeRaw(fd uintptr) (func() error, error) {
+	org, err := getTermios(fd)
+	if err != nil {
+		return nil, ErrNotTerminal
+	}
+	t := *org
+	t.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG
+	t.Cc[syscall.VMIN] = 1
+	t.Cc[syscall.VTIME] = 0
+	if err := setTermios(fd, &t); err != nil {
+		return nil, err
+	}
+	return func() error { return setTermios(fd, org) }, nil
+}
+
+type winsize struct {
+	row, col, x, y uint16
+}
+
+func width(fd uintptr) (int, bool) {
+	ws := &winsize{}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(ws))); errno != 0 {
+		return 0, false
+	}
+	if ws.col == 0 {
+		return 0, false
+	}
+	return int(ws.col), true
+}
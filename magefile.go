@@ -3,6 +3,10 @@
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
 	"github.com/magefile/mage/mg"
 	"github.com/magefile/mage/sh"
 )
@@ -12,9 +16,9 @@ var deps = []string{
 	"github.com/CircleCI-Public/circleci-cli",
 }
 
-// Test run go test.
+// Test run go test with race detector.
 func Test() {
-	sh.RunV("go", "test", "./...")
+	sh.RunV("go", "test", "-race", "./...")
 }
 
 // Tidy add/remove depenedencies.
@@ -34,6 +38,22 @@ func Lint() {
 	sh.RunV("golangci-lint", "run", "--enable-all", "--disable=scopelint,lll,maligned")
 }
 
+// Ldflags print ldflags which inject VERSION and git revision to version output.
+// go build -ldflags "$(mage ldflags)"
+func Ldflags() error {
+	version, err := ioutil.ReadFile("VERSION")
+	if err != nil {
+		return err
+	}
+	revision, err := sh.Output("git", "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	const pkg = "github.com/ymgyt/cli"
+	fmt.Printf("-X %s.BuildVersion=%s -X %s.BuildRevision=%s\n", pkg, strings.TrimSpace(string(version)), pkg, revision)
+	return nil
+}
+
 type Coverage mg.Namespace
 
 func (Coverage) Func() {
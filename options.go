@@ -1,6 +1,10 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ymgyt/cli/flags"
@@ -10,12 +14,57 @@ func (c *Command) Options() *OptionConfigurator {
 	if c.flagSet == nil {
 		c.flagSet = &flags.FlagSet{}
 	}
-	return &OptionConfigurator{cmd: c}
+	return &OptionConfigurator{cmd: c, fs: c.flagSet}
 }
 
+// PersistentOptions configure flags which are also available in sub commands.
+func (c *Command) PersistentOptions() *OptionConfigurator {
+	if c.persistentFlagSet == nil {
+		c.persistentFlagSet = &flags.FlagSet{}
+	}
+	return &OptionConfigurator{cmd: c, fs: c.persistentFlagSet}
+}
+
+// OptionConfigurator add flags to command.
+// errors of each Add are accumulated to Err as OptionErrors.
 type OptionConfigurator struct {
-	Err error
-	cmd *Command
+	Err  error
+	errs OptionErrors
+	cmd  *Command
+	fs   *flags.FlagSet
+}
+
+// OptionError describes the flag which could not be added.
+type OptionError struct {
+	Flag string
+	Err  error
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("flag %s: %s", e.Flag, e.Err)
+}
+
+func (e *OptionError) Unwrap() error { return e.Err }
+
+// OptionErrors is set to OptionConfigurator.Err.
+type OptionErrors []*OptionError
+
+func (es OptionErrors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether any error matches target.
+func (es OptionErrors) Is(target error) bool {
+	for _, e := range es {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
 }
 
 type FlagProvider interface {
@@ -23,114 +72,347 @@ type FlagProvider interface {
 }
 
 type StringOpt struct {
-	Var         *string
-	Long        string
-	Short       string
-	Default     string
-	Description string
-	Aliases     []string
+	Var               *string
+	Long              string
+	Short             string
+	Default           string
+	Description       string
+	Category          string
+	Aliases           []string
+	Hidden            bool
+	Deprecated        string
+	DeprecatedAliases map[string]string
+	EnvVar            string
+	FromFile          bool
+	Secret            bool
+	Prompt            string
 }
 
 func (o *StringOpt) Flag() *flags.Flag {
 	*o.Var = o.Default
 	v := (*flags.StringVar)(o.Var)
-	return &flags.Flag{Long: o.Long, Short: o.Short, Description: o.Description, Aliases: o.Aliases, Var: v}
+	return &flags.Flag{
+		Long:              o.Long,
+		Short:             o.Short,
+		Description:       o.Description,
+		Category:          o.Category,
+		Aliases:           o.Aliases,
+		EnvVar:            o.EnvVar,
+		Var:               v,
+		Default:           defaultText(o.Default != "" && !o.Secret, o.Default),
+		FromFile:          o.FromFile,
+		Secret:            o.Secret,
+		Prompt:            o.Prompt,
+		Hidden:            o.Hidden,
+		Deprecated:        o.Deprecated,
+		DeprecatedAliases: o.DeprecatedAliases,
+		ResetVar:          func() { *o.Var = o.Default },
+	}
 }
 
 type IntOpt struct {
-	Var         *int
-	Long        string
-	Short       string
-	Default     int
-	Description string
-	Aliases     []string
+	Var               *int
+	Long              string
+	Short             string
+	Default           int
+	Description       string
+	Category          string
+	Aliases           []string
+	Hidden            bool
+	Deprecated        string
+	DeprecatedAliases map[string]string
+	EnvVar            string
+	FromFile          bool
 }
 
 func (o *IntOpt) Flag() *flags.Flag {
 	*o.Var = o.Default
 	v := (*flags.IntVar)(o.Var)
-	return &flags.Flag{Long: o.Long, Short: o.Short, Description: o.Description, Aliases: o.Aliases, Var: v}
+	return &flags.Flag{
+		Long:              o.Long,
+		Short:             o.Short,
+		Description:       o.Description,
+		Category:          o.Category,
+		Aliases:           o.Aliases,
+		EnvVar:            o.EnvVar,
+		Var:               v,
+		Default:           defaultText(o.Default != 0, strconv.Itoa(o.Default)),
+		FromFile:          o.FromFile,
+		Hidden:            o.Hidden,
+		Deprecated:        o.Deprecated,
+		DeprecatedAliases: o.DeprecatedAliases,
+		ResetVar:          func() { *o.Var = o.Default },
+	}
 }
 
 type FloatOpt struct {
-	Var         *float64
-	Long        string
-	Short       string
-	Default     float64
-	Description string
-	Aliases     []string
+	Var               *float64
+	Long              string
+	Short             string
+	Default           float64
+	Description       string
+	Category          string
+	Aliases           []string
+	Hidden            bool
+	Deprecated        string
+	DeprecatedAliases map[string]string
+	EnvVar            string
+	FromFile          bool
 }
 
 func (o *FloatOpt) Flag() *flags.Flag {
 	*o.Var = o.Default
 	v := (*flags.FloatVar)(o.Var)
-	return &flags.Flag{Long: o.Long, Short: o.Short, Description: o.Description, Aliases: o.Aliases, Var: v}
+	return &flags.Flag{
+		Long:              o.Long,
+		Short:             o.Short,
+		Description:       o.Description,
+		Category:          o.Category,
+		Aliases:           o.Aliases,
+		EnvVar:            o.EnvVar,
+		Var:               v,
+		Default:           defaultText(o.Default != 0, strconv.FormatFloat(o.Default, 'g', -1, 64)),
+		FromFile:          o.FromFile,
+		Hidden:            o.Hidden,
+		Deprecated:        o.Deprecated,
+		DeprecatedAliases: o.DeprecatedAliases,
+		ResetVar:          func() { *o.Var = o.Default },
+	}
 }
 
 type BoolOpt struct {
-	Var         *bool
-	Long        string
-	Short       string
-	Default     bool
-	Description string
-	Aliases     []string
+	Var               *bool
+	Long              string
+	Short             string
+	Default           bool
+	Description       string
+	Category          string
+	Aliases           []string
+	Hidden            bool
+	Deprecated        string
+	DeprecatedAliases map[string]string
+	EnvVar            string
 }
 
 func (o *BoolOpt) Flag() *flags.Flag {
 	*o.Var = o.Default
 	v := (*flags.BoolVar)(o.Var)
-	return &flags.Flag{Long: o.Long, Short: o.Short, Description: o.Description, Aliases: o.Aliases, Var: v}
+	return &flags.Flag{
+		Long:              o.Long,
+		Short:             o.Short,
+		Description:       o.Description,
+		Category:          o.Category,
+		Aliases:           o.Aliases,
+		EnvVar:            o.EnvVar,
+		Var:               v,
+		Default:           defaultText(o.Default, strconv.FormatBool(o.Default)),
+		Hidden:            o.Hidden,
+		Deprecated:        o.Deprecated,
+		DeprecatedAliases: o.DeprecatedAliases,
+		ResetVar:          func() { *o.Var = o.Default },
+	}
 }
 
 type StringsOpt struct {
-	Var         *[]string
-	Long        string
-	Short       string
-	Default     []string
-	Description string
-	Aliases     []string
-	Delimiter   string
+	Var               *[]string
+	Long              string
+	Short             string
+	Default           []string
+	Description       string
+	Category          string
+	Aliases           []string
+	Hidden            bool
+	Deprecated        string
+	DeprecatedAliases map[string]string
+	EnvVar            string
+	FromFile          bool
+	Delimiter         string
 }
 
 func (o *StringsOpt) Flag() *flags.Flag {
-	*o.Var = o.Default
+	*o.Var = append([]string(nil), o.Default...)
 	v := (*flags.StringsVar)(o.Var)
-	return &flags.Flag{Long: o.Long, Short: o.Short, Description: o.Description, Aliases: o.Aliases, Var: v, AllowMultipleTimesSet: true, Delimiter: o.Delimiter}
+	return &flags.Flag{
+		Long:                  o.Long,
+		Short:                 o.Short,
+		Description:           o.Description,
+		Category:              o.Category,
+		Aliases:               o.Aliases,
+		EnvVar:                o.EnvVar,
+		Var:                   v,
+		Default:               defaultText(len(o.Default) > 0, strings.Join(o.Default, delimiterText(o.Delimiter))),
+		FromFile:              o.FromFile,
+		AllowMultipleTimesSet: true,
+		Delimiter:             o.Delimiter,
+		Hidden:                o.Hidden,
+		Deprecated:            o.Deprecated,
+		DeprecatedAliases:     o.DeprecatedAliases,
+		ResetVar:              func() { *o.Var = append([]string(nil), o.Default...) },
+	}
 }
 
 type IntsOpt struct {
-	Var         *[]int
-	Long        string
-	Short       string
-	Default     []int
-	Description string
-	Aliases     []string
-	Delimiter   string
+	Var               *[]int
+	Long              string
+	Short             string
+	Default           []int
+	Description       string
+	Category          string
+	Aliases           []string
+	Hidden            bool
+	Deprecated        string
+	DeprecatedAliases map[string]string
+	EnvVar            string
+	FromFile          bool
+	Delimiter         string
 }
 
 func (o *IntsOpt) Flag() *flags.Flag {
-	*o.Var = o.Default
+	*o.Var = append([]int(nil), o.Default...)
 	v := (*flags.IntsVar)(o.Var)
-	return &flags.Flag{Long: o.Long, Short: o.Short, Description: o.Description, Aliases: o.Aliases, Var: v, AllowMultipleTimesSet: true, Delimiter: o.Delimiter}
+	ds := make([]string, 0, len(o.Default))
+	for _, d := range o.Default {
+		ds = append(ds, strconv.Itoa(d))
+	}
+	return &flags.Flag{
+		Long:                  o.Long,
+		Short:                 o.Short,
+		Description:           o.Description,
+		Category:              o.Category,
+		Aliases:               o.Aliases,
+		EnvVar:                o.EnvVar,
+		Var:                   v,
+		Default:               strings.Join(ds, delimiterText(o.Delimiter)),
+		FromFile:              o.FromFile,
+		AllowMultipleTimesSet: true,
+		Delimiter:             o.Delimiter,
+		Hidden:                o.Hidden,
+		Deprecated:            o.Deprecated,
+		DeprecatedAliases:     o.DeprecatedAliases,
+		ResetVar:              func() { *o.Var = append([]int(nil), o.Default...) },
+	}
 }
 
 type DurationOpt struct {
-	Var         *time.Duration
-	Long        string
-	Short       string
-	Default     time.Duration
-	Description string
-	Aliases     []string
+	Var               *time.Duration
+	Long              string
+	Short             string
+	Default           time.Duration
+	Description       string
+	Category          string
+	Aliases           []string
+	Hidden            bool
+	Deprecated        string
+	DeprecatedAliases map[string]string
+	EnvVar            string
+	FromFile          bool
 }
 
 func (o *DurationOpt) Flag() *flags.Flag {
 	*o.Var = o.Default
 	v := (*flags.DurationVar)(o.Var)
-	return &flags.Flag{Long: o.Long, Short: o.Short, Description: o.Description, Aliases: o.Aliases, Var: v}
+	return &flags.Flag{
+		Long:              o.Long,
+		Short:             o.Short,
+		Description:       o.Description,
+		Category:          o.Category,
+		Aliases:           o.Aliases,
+		EnvVar:            o.EnvVar,
+		Var:               v,
+		Default:           defaultText(o.Default != 0, o.Default.String()),
+		FromFile:          o.FromFile,
+		Hidden:            o.Hidden,
+		Deprecated:        o.Deprecated,
+		DeprecatedAliases: o.DeprecatedAliases,
+		ResetVar:          func() { *o.Var = o.Default },
+	}
+}
+
+func defaultText(nonZero bool, text string) string {
+	if !nonZero {
+		return ""
+	}
+	return text
+}
+
+func delimiterText(delimiter string) string {
+	if delimiter == "" {
+		return ","
+	}
+	return delimiter
+}
+
+// DeprecatedOpt is a flag kept for compatibility.
+// its value is set to ReplacedBy flag, which must be added before.
+type DeprecatedOpt struct {
+	Long       string
+	Short      string
+	Aliases    []string
+	ReplacedBy string
+	Message    string
+}
+
+func (o *DeprecatedOpt) Flag() *flags.Flag {
+	msg := o.Message
+	if msg == "" {
+		msg = fmt.Sprintf("use --%s instead", o.ReplacedBy)
+	}
+	return &flags.Flag{
+		Long:       o.Long,
+		Short:      o.Short,
+		Aliases:    o.Aliases,
+		Hidden:     true,
+		Deprecated: msg,
+		ReplacedBy: o.ReplacedBy,
+	}
 }
 
+// Add add flag provided by provider.
+// if flag is allowed to be read from file, "--<long>-file" flag is also added.
 func (c *OptionConfigurator) Add(provider FlagProvider) *OptionConfigurator {
-	fs := c.cmd.flagSet
-	c.Err = fs.Add(provider.Flag())
+	fs := c.fs
+	f := provider.Flag()
+	if f.ReplacedBy != "" {
+		replacement, err := fs.Lookup(f.ReplacedBy)
+		if err != nil {
+			return c.addErr(f, fmt.Errorf("replaced by --%s: %w", f.ReplacedBy, err))
+		}
+		f.Var = replacement.Var
+	}
+	if err := fs.Add(f); err != nil {
+		return c.addErr(f, err)
+	}
+	if f.FromFile && f.Long != "" {
+		ff := &flags.Flag{
+			Long:        f.Long + "-file",
+			Var:         &flags.FileVar{Target: f},
+			Secret:      f.Secret,
+			Description: fmt.Sprintf("read --%s from file", f.Long),
+		}
+		if err := fs.Add(ff); err != nil {
+			return c.addErr(ff, err)
+		}
+	}
+	return c
+}
+
+func (c *OptionConfigurator) addErr(f *flags.Flag, err error) *OptionConfigurator {
+	name := "(no name)"
+	if f.Name() != "" {
+		name = flagDisplayName(f.Name())
+	}
+	oe := &OptionError{Flag: name, Err: err}
+	c.errs = append(c.errs, oe)
+	c.Err = c.errs
+	if c.cmd != nil {
+		c.cmd.optionErrs = append(c.cmd.optionErrs, oe)
+	}
 	return c
 }
+
+// Must panics if any Add failed. it is intended to be used in init code.
+func (c *OptionConfigurator) Must() {
+	if c.Err != nil {
+		panic(fmt.Sprintf("%s: invalid options\n%s", c.cmd.Path(), c.Err))
+	}
+}
@@ -1,10 +1,18 @@
 package cli_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ymgyt/cli"
+	"github.com/ymgyt/cli/flags"
 )
 
 func TestOptionConfigurator_Add(t *testing.T) {
@@ -67,3 +75,133 @@ func TestOptionConfigurator_Add(t *testing.T) {
 		t.Fatalf("Command.Options().Add() %v", err)
 	}
 }
+
+func TestOptionConfigurator_Add_fromFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "options")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("s3cr3t\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	tests := map[string][]string{
+		"at prefix":  {"--token=@" + f.Name()},
+		"file flag":  {"--token-file", f.Name()},
+		"short flag": {"-t", "@" + f.Name()},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			var token string
+			cmd := &cli.Command{Name: "root", Run: func(_ context.Context, _ *cli.Command, _ []string) {}}
+			err := cmd.Options().Add(&cli.StringOpt{Var: &token, Long: "token", Short: "t", FromFile: true}).Err
+			if err != nil {
+				t.Fatal(err)
+			}
+			cmd.ExecuteWithArgs(context.Background(), args)
+			if token != "s3cr3t" {
+				t.Errorf("got %q, want %q", token, "s3cr3t")
+			}
+		})
+	}
+}
+
+func TestOptionConfigurator_Add_envVar(t *testing.T) {
+	const env = "CLI_TEST_TOKEN"
+	os.Setenv(env, "from-env")
+	defer os.Unsetenv(env)
+
+	tests := map[string]struct {
+		args []string
+		want string
+	}{
+		"env":                  {want: "from-env"},
+		"command line precede": {args: []string{"--token=from-args"}, want: "from-args"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var token string
+			cmd := &cli.Command{Name: "root", Run: func(_ context.Context, _ *cli.Command, _ []string) {}}
+			cmd.Options().Add(&cli.StringOpt{Var: &token, Long: "token", EnvVar: env, Secret: true, Prompt: "token: "})
+			cmd.ExecuteWithArgs(context.Background(), tc.args)
+			if token != tc.want {
+				t.Errorf("got %q, want %q", token, tc.want)
+			}
+		})
+	}
+
+	t.Run("invalid env value", func(t *testing.T) {
+		const pinEnv = "CLI_TEST_PIN"
+		os.Setenv(pinEnv, "abc123")
+		defer os.Unsetenv(pinEnv)
+
+		var pin int
+		stderr := &bytes.Buffer{}
+		cmd := &cli.Command{Name: "root", Stderr: stderr, Run: func(_ context.Context, _ *cli.Command, _ []string) {
+			t.Error("Run should not be called")
+		}}
+		cmd.Options().Add(&cli.IntOpt{Var: &pin, Long: "pin", EnvVar: pinEnv})
+		cmd.ExecuteWithArgs(context.Background(), nil)
+		if stderr.Len() == 0 {
+			t.Error("want parse error message")
+		}
+	})
+}
+
+func TestOptionConfigurator_Add_secret(t *testing.T) {
+	var token string
+	stderr := &bytes.Buffer{}
+	cmd := &cli.Command{Name: "root", Stderr: stderr, Run: func(_ context.Context, _ *cli.Command, _ []string) {}}
+	cmd.Options().Add(&cli.StringOpt{Var: &token, Long: "token", Default: "default-s3cr3t", Secret: true, Description: "api token"})
+
+	var b strings.Builder
+	cli.HelpFunc(&b, cmd)
+	if strings.Contains(b.String(), "default-s3cr3t") {
+		t.Errorf("secret default value should not be displayed. got %s", b.String())
+	}
+
+	cmd.ExecuteWithArgs(context.Background(), []string{"--token=s3cr3t", "--token=s3cr3t"})
+	if strings.Contains(stderr.String(), "s3cr3t") {
+		t.Errorf("secret value should not be displayed. got %s", stderr.String())
+	}
+}
+
+func TestOptionConfigurator_Add_errors(t *testing.T) {
+	cmd := &cli.Command{Name: "app"}
+	var label, name string
+	var level int
+	c := cmd.Options().
+		Add(&cli.StringOpt{Var: &label, Long: "label"}).
+		Add(&cli.StringOpt{Var: &name, Long: "label"}).
+		Add(&cli.IntOpt{Var: &level, Long: "level"}).
+		Add(&cli.DeprecatedOpt{Long: "lvl", ReplacedBy: "verbosity"}).
+		Add(&cli.StringOpt{Var: &name, Long: "name"})
+
+	want := "flag --label: flag already exists\nflag --lvl: replaced by --verbosity: flag not found"
+	if c.Err == nil || c.Err.Error() != want {
+		t.Fatalf("got %v, want %s", c.Err, want)
+	}
+	for _, target := range []error{flags.ErrFlagAlreadyExists, flags.ErrFlagNotFound} {
+		if !errors.Is(c.Err, target) {
+			t.Errorf("errors.Is(err, %v) = false", target)
+		}
+	}
+	if len(cmd.Flags()) != 3 {
+		t.Errorf("flags after failed Add should be added, got %d", len(cmd.Flags()))
+	}
+	if err := cmd.Validate(); !errors.Is(err, flags.ErrFlagAlreadyExists) {
+		t.Errorf("Validate() should report option errors, got %v", err)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(fmt.Sprint(r), want) {
+			t.Errorf("Must() should panic with report, got %v", r)
+		}
+	}()
+	c.Must()
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorKind is a kind of parse error.
+type ErrorKind int
+
+const (
+	KindUnknown ErrorKind = iota
+	KindUnknownFlag
+	KindMissingValue
+	KindInvalidValue
+	KindInvalidCluster
+	KindResponseFile
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindUnknownFlag:
+		return "unknown flag"
+	case KindMissingValue:
+		return "missing value"
+	case KindInvalidValue:
+		return "invalid value"
+	case KindInvalidCluster:
+		return "invalid cluster"
+	case KindResponseFile:
+		return "response file"
+	default:
+		return "unknown"
+	}
+}
+
+type Error struct {
+	Flag string
+	Msg  string
+	Kind ErrorKind
+	// Index is a position of the offending argument in Args.
+	Index int
+	// Offset is a byte offset of the offending part in the argument.
+	Offset int
+	// Path is a path of the command at the point of failure like "root sub".
+	Path string
+	// Args is parsed arguments. it is used to render diagnostics.
+	Args []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s", e.Flag, e.Msg)
+}
+
+// Render return the command line and a caret line under the offending argument like
+//
+//	root sub --label
+//	         ^^^^^^^
+//
+// empty string is returned if Args is not available.
+func (e *Error) Render() string {
+	if e.Index < 0 || e.Index >= len(e.Args) {
+		return ""
+	}
+	line := strings.Fields(e.Path)
+	if len(line) > 0 {
+		line = line[:1]
+	}
+	col := 0
+	for _, s := range line {
+		col += len(s) + 1
+	}
+	for i, arg := range e.Args {
+		if i < e.Index {
+			col += len(arg) + 1
+		}
+		line = append(line, arg)
+	}
+	arg := e.Args[e.Index]
+	offset := e.Offset
+	if offset < 0 || offset >= len(arg) {
+		offset = 0
+	}
+	width := len(arg) - offset
+	if e.Kind == KindInvalidCluster && offset > 0 {
+		width = 1
+	}
+	if width < 1 {
+		width = 1
+	}
+	return fmt.Sprintf("%s\n%s%s\n", strings.Join(line, " "), strings.Repeat(" ", col+offset), strings.Repeat("^", width))
+}
@@ -0,0 +1,72 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli/parser"
+)
+
+func TestParser_Parse_errorPosition(t *testing.T) {
+	root := &fakeCmd{
+		name: "root",
+		subs: []*fakeCmd{{name: "sub", boolFlags: []string{"a", "b"}}},
+	}
+	tests := map[string]struct {
+		args   []string
+		want   *parser.Error
+		render string
+	}{
+		"missing value": {
+			args: []string{"sub", "--label"},
+			want: &parser.Error{Flag: "--label", Kind: parser.KindMissingValue, Index: 1, Path: "root sub"},
+			render: `root sub --label
+         ^^^^^^^
+`,
+		},
+		"invalid value": {
+			args: []string{"sub", "-a=yes"},
+			want: &parser.Error{Flag: "-a=yes", Kind: parser.KindInvalidValue, Index: 1, Offset: 3, Path: "root sub"},
+			render: `root sub -a=yes
+            ^^^
+`,
+		},
+		"invalid cluster": {
+			args: []string{"sub", "x", "-abc"},
+			want: &parser.Error{Flag: "c", Kind: parser.KindInvalidCluster, Index: 2, Offset: 3, Path: "root sub"},
+			render: `root sub x -abc
+              ^
+`,
+		},
+	}
+	ignore := func(p cmp.Path) bool {
+		return p.Last().String() == ".Msg" || p.Last().String() == ".Args"
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parser.New(root).Parse(tc.args)
+			pe, ok := err.(*parser.Error)
+			if !ok {
+				t.Fatalf("want *parser.Error, got %v", err)
+			}
+			if diff := cmp.Diff(pe, tc.want, cmp.FilterPath(ignore, cmp.Ignore())); diff != "" {
+				t.Errorf("(-got +want)%s", diff)
+			}
+			if diff := cmp.Diff(pe.Render(), tc.render); diff != "" {
+				t.Errorf("render (-got +want)%s", diff)
+			}
+		})
+	}
+}
+
+func TestError_Render_withoutArgs(t *testing.T) {
+	err := &parser.Error{Flag: "--x", Kind: parser.KindUnknownFlag, Index: 3}
+	if got := err.Render(); got != "" {
+		t.Errorf("got %q, want empty", got)
+	}
+	if got := err.Kind.String(); got != "unknown flag" {
+		t.Errorf("got %q", got)
+	}
+}
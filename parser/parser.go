@@ -9,49 +9,86 @@ import (
 type Result struct {
 	commands []string
 	args     []string
+	flags    []*Flag
 	flagMap  map[string][]*Flag
+	pathMap  map[string][]*Flag
 
+	input       []string
 	rootCmdName string
+	path        string
 }
 
 func (r *Result) Commands() []string { return r.commands }
 func (r *Result) Args() []string     { return r.args }
+
+// Flags return flags given to the command of name. if nested commands share the name, use FlagsByPath.
 func (r *Result) Flags(cmd string) []*Flag {
 	return r.flagMap[cmd]
 }
-func (r *Result) AllFlags() []*Flag {
-	var fs []*Flag //nolint: prealloc
-	for _, fss := range r.flagMap {
-		fs = append(fs, fss...)
-	}
-	return fs
+
+// FlagsByPath return flags given to the command of path like "root sub subsub" in command line order.
+func (r *Result) FlagsByPath(path string) []*Flag {
+	return r.pathMap[path]
 }
 
+// Input return args given to Parse. if response files are enabled, they are expanded.
+func (r *Result) Input() []string { return r.input }
+
+// AllFlags return flags in command line order.
+func (r *Result) AllFlags() []*Flag { return r.flags }
+
 type Flag struct {
 	Name      string
 	Value     string
 	IsBool    bool
 	BoolValue bool
+	// Index is a position of the argument in args. flags of "-abc" share the same Index.
+	Index int
+	// Path is a path of the command which the flag is given to like "root sub".
+	Path string
 }
 
 type Commander interface {
 	Name() string
 	LookupSubCommand(name string) (Commander, bool)
 	IsBoolFlag(name string) bool
+	// HasFlag reports whether the command or its ancestors define the flag. it is used by PassThroughUnknownFlags.
+	HasFlag(name string) bool
+	ArgsMode() ArgsMode
 }
 
+// ArgsMode decides how flags and positional arguments are mixed.
+type ArgsMode int
+
+const (
+	// Interspersed parses flags until "--" regardless of positional arguments.
+	Interspersed ArgsMode = iota
+	// StopAtFirstArg treats the first positional argument and the rest as arguments untouched.
+	StopAtFirstArg
+	// PassThroughUnknownFlags treats unknown flags as arguments.
+	// after unknown flag, words are not looked up as sub commands, while known flags are still parsed.
+	PassThroughUnknownFlags
+)
+
 func New(root Commander) *Parser {
 	return &Parser{Root: root}
 }
 
 type Parser struct {
 	Root Commander
+	// ResponseFiles enables "@path" arguments expansion.
+	ResponseFiles bool
 }
 
 func (p *Parser) Parse(args []string) (*Result, error) {
-	lexer := newLexer(args)
+	lexer, err := newLexer(args, p.ResponseFiles)
+	if err != nil {
+		return nil, err
+	}
 
 	ctx := newContext(p.Root.Name())
+	ctx.input = lexer.args
+	ctx.args = make([]string, 0, len(lexer.args))
 	parse(lexer, p.Root, ctx)
 	if ctx.err != nil {
 		return nil, ctx.err
@@ -60,85 +97,110 @@ func (p *Parser) Parse(args []string) (*Result, error) {
 }
 
 func parse(lexer *lexer, cmd Commander, ctx *context) {
-	if ctx.err != nil {
-		return
-	}
-	tk := lexer.read()
-	switch tk.kind {
-	case tkEnd:
-		return
-	case tkArgument:
-		parseArgument(tk, lexer, &cmd, ctx)
-	case tkFlag:
-		if cmd.IsBoolFlag(tk.flagName) {
-			parseBoolFlag(tk, lexer, cmd, ctx)
-		} else {
-			parseFlag(tk, lexer, cmd, ctx)
+	for ctx.err == nil {
+		tk := lexer.read()
+		if cmd.ArgsMode() == PassThroughUnknownFlags && tk.isFlag() && !knownFlag(tk, cmd) {
+			ctx.addArg(tk.raw)
+			ctx.passedThrough = true
+			continue
 		}
-	case tkFlagWithValue:
-		if cmd.IsBoolFlag(tk.flagName) {
-			parseBoolFlagWithValue(tk, lexer, cmd, ctx)
-		} else {
-			parseFlagWithValue(tk, lexer, cmd, ctx)
+		switch tk.kind {
+		case tkEnd:
+			return
+		case tkArgument:
+			parseArgument(tk, lexer, &cmd, ctx)
+		case tkFlag:
+			if cmd.IsBoolFlag(tk.flagName) {
+				parseBoolFlag(tk, lexer, cmd, ctx)
+			} else {
+				parseFlag(tk, lexer, cmd, ctx)
+			}
+		case tkFlagWithValue:
+			if cmd.IsBoolFlag(tk.flagName) {
+				parseBoolFlagWithValue(tk, lexer, cmd, ctx)
+			} else {
+				parseFlagWithValue(tk, lexer, cmd, ctx)
+			}
+		case tkMultiFlag:
+			parseMultiFlag(tk, lexer, cmd, ctx)
+		case tkTermination:
+			parseTermination(lexer, cmd, ctx)
+		case tkInvalid:
+			ctx.err = ctx.newError(KindInvalidCluster, tk, 0, tk.raw, "multi short flags can not have value")
 		}
-	case tkMultiFlag:
-		parseMultiFlag(tk, lexer, cmd, ctx)
-	case tkTermination:
-		parseTermination(lexer, cmd, ctx)
-	case tkInvalid:
-		ctx.err = &Error{Flag: tk.raw}
 	}
+}
 
-	parse(lexer, cmd, ctx)
+func parseArgument(tk token, lexer *lexer, cmd *Commander, ctx *context) {
+	if !ctx.passedThrough {
+		if sub, found := (*cmd).LookupSubCommand(tk.raw); found {
+			ctx.addCmd(tk.raw)
+			*cmd = sub
+			return
+		}
+	}
+	ctx.addArg(tk.raw)
+	if (*cmd).ArgsMode() == StopAtFirstArg {
+		ctx.args = append(ctx.args, lexer.readAllAsLiteral()...)
+	}
 }
 
-func parseArgument(tk token, _ *lexer, cmd *Commander, ctx *context) {
-	sub, found := (*cmd).LookupSubCommand(tk.raw)
-	if found {
-		ctx.addCmd(tk.raw)
-		*cmd = sub
-	} else {
-		ctx.addArg(tk.raw)
+// knownFlag reports whether all flags of the token are defined.
+func knownFlag(tk token, cmd Commander) bool {
+	switch tk.kind {
+	case tkFlag, tkFlagWithValue:
+		return cmd.HasFlag(tk.flagName)
+	case tkMultiFlag:
+		for _, r := range tk.flagName {
+			if !cmd.HasFlag(string(r)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
 	}
 }
 
+func (tk token) isFlag() bool {
+	return tk.kind == tkFlag || tk.kind == tkFlagWithValue || tk.kind == tkMultiFlag || tk.kind == tkInvalid
+}
+
 func parseBoolFlag(tk token, _ *lexer, cmd Commander, ctx *context) {
-	ctx.addBoolFlag(cmd.Name(), tk)
+	ctx.addFlag(cmd.Name(), tk, &Flag{Name: tk.flagName, IsBool: true, BoolValue: true})
 }
 
 func parseFlag(tk token, lexer *lexer, cmd Commander, ctx *context) {
 	next := lexer.read()
 	if next.kind != tkArgument {
-		ctx.err = &Error{Flag: tk.raw, Msg: "value not provided"}
+		ctx.err = ctx.newError(KindMissingValue, tk, 0, tk.raw, "value not provided")
 		return
 	}
-	ctx.addFlag(cmd.Name(), &Flag{Name: tk.flagName, Value: next.raw})
+	ctx.addFlag(cmd.Name(), tk, &Flag{Name: tk.flagName, Value: next.raw})
 }
 
 func parseBoolFlagWithValue(tk token, _ *lexer, cmd Commander, ctx *context) {
 	b, err := strconv.ParseBool(tk.flagValue)
 	if err != nil {
-		ctx.err = &Error{Flag: tk.raw, Msg: fmt.Sprintf("invalid bool value %q", tk.flagValue)}
+		ctx.err = ctx.newError(KindInvalidValue, tk, strings.Index(tk.raw, "=")+1, tk.raw, fmt.Sprintf("invalid bool value %q", tk.flagValue))
 		return
 	}
-	ctx.addFlag(cmd.Name(), &Flag{Name: tk.flagName, IsBool: true, BoolValue: b})
+	ctx.addFlag(cmd.Name(), tk, &Flag{Name: tk.flagName, IsBool: true, BoolValue: b})
 }
 
 func parseFlagWithValue(tk token, _ *lexer, cmd Commander, ctx *context) {
-	ctx.addFlag(cmd.Name(), &Flag{Name: tk.flagName, Value: tk.flagValue})
+	ctx.addFlag(cmd.Name(), tk, &Flag{Name: tk.flagName, Value: tk.flagValue})
 }
 
 func parseMultiFlag(tk token, _ *lexer, cmd Commander, ctx *context) {
-	for _, r := range tk.flagName {
+	for i, r := range tk.flagName {
 		flag := string(r)
 		if !cmd.IsBoolFlag(flag) {
-			ctx.err = &Error{
-				Flag: flag,
-				Msg:  fmt.Sprintf("%s (%s) only bool flag is allowed as multi short flag.", tk.raw, flag),
-			}
+			ctx.err = ctx.newError(KindInvalidCluster, tk, i+1, flag,
+				fmt.Sprintf("%s (%s) only bool flag is allowed as multi short flag.", tk.raw, flag))
 			return
 		}
-		ctx.addFlag(cmd.Name(), &Flag{Name: flag, IsBool: true, BoolValue: true})
+		ctx.addFlag(cmd.Name(), tk, &Flag{Name: flag, IsBool: true, BoolValue: true})
 	}
 }
 
@@ -149,36 +211,48 @@ func parseTermination(lexer *lexer, _ Commander, ctx *context) {
 	}
 }
 
-type Error struct {
-	Flag string
-	Msg  string
-}
-
-func (e *Error) Error() string {
-	return fmt.Sprintf("%s %s", e.Flag, e.Msg)
-}
-
 type context struct {
 	*Result
 	err error
+	// passedThrough is set when unknown flag is passed through. following words are not sub commands
+	// because they may be values of the flag.
+	passedThrough bool
 }
 
 func newContext(rootName string) *context {
 	return &context{
 		Result: &Result{
 			flagMap:     make(map[string][]*Flag),
+			pathMap:     make(map[string][]*Flag),
 			rootCmdName: rootName,
+			path:        rootName,
 		},
 	}
 }
 
+func (ctx *context) newError(kind ErrorKind, tk token, offset int, flag, msg string) *Error {
+	return &Error{
+		Flag:   flag,
+		Msg:    msg,
+		Kind:   kind,
+		Index:  tk.index,
+		Offset: offset,
+		Path:   ctx.path,
+		Args:   ctx.input,
+	}
+}
+
 func (ctx *context) addArg(s string) { ctx.args = append(ctx.args, s) }
-func (ctx *context) addCmd(s string) { ctx.commands = append(ctx.commands, s) }
-func (ctx *context) addFlag(cmd string, f *Flag) {
-	ctx.flagMap[cmd] = append(ctx.flagMap[cmd], f)
+func (ctx *context) addCmd(s string) {
+	ctx.commands = append(ctx.commands, s)
+	ctx.path += " " + s
 }
-func (ctx *context) addBoolFlag(cmd string, t token) {
-	ctx.addFlag(cmd, &Flag{Name: t.flagName, IsBool: true, BoolValue: true})
+func (ctx *context) addFlag(cmd string, tk token, f *Flag) {
+	f.Index = tk.index
+	f.Path = ctx.path
+	ctx.flags = append(ctx.flags, f)
+	ctx.flagMap[cmd] = append(ctx.flagMap[cmd], f)
+	ctx.pathMap[ctx.path] = append(ctx.pathMap[ctx.path], f)
 }
 
 type lexer struct {
@@ -186,15 +260,15 @@ type lexer struct {
 	current int
 }
 
-func newLexer(args []string) *lexer {
-	stripped := make([]string, 0, len(args))
-	for _, arg := range args {
-		if arg == "" {
-			continue
+func newLexer(args []string, responseFiles bool) (*lexer, error) {
+	if responseFiles {
+		expanded, err := expandResponseFiles(args)
+		if err != nil {
+			return nil, err
 		}
-		stripped = append(stripped, arg)
+		args = expanded
 	}
-	return &lexer{args: stripped}
+	return &lexer{args: args}, nil
 }
 
 type tokenKind int
@@ -214,15 +288,22 @@ type token struct {
 	raw       string
 	flagName  string
 	flagValue string
+	index     int
 }
 
+// read return next token. empty args are ignored.
 func (l *lexer) read() token {
+	for !l.isEnd() && l.args[l.current] == "" {
+		l.current++
+	}
 	if l.isEnd() {
 		return token{kind: tkEnd}
 	}
 	v := l.args[l.current]
-	defer func() { l.current++ }()
-	return l.toToken(v)
+	tk := l.toToken(v)
+	tk.index = l.current
+	l.current++
+	return tk
 }
 
 func (l *lexer) isEnd() bool { return l.current >= len(l.args) }
@@ -235,9 +316,8 @@ func (l *lexer) toToken(v string) token {
 
 		fName := v[2:]
 		// --label=app
-		if strings.Contains(v, "=") {
-			nameValue := strings.SplitN(fName, "=", 2)
-			return token{kind: tkFlagWithValue, raw: v, flagName: nameValue[0], flagValue: nameValue[1]}
+		if i := strings.IndexByte(fName, '='); i >= 0 {
+			return token{kind: tkFlagWithValue, raw: v, flagName: fName[:i], flagValue: fName[i+1:]}
 		}
 		return token{kind: tkFlag, raw: v, flagName: fName}
 	}
@@ -254,11 +334,10 @@ func (l *lexer) toToken(v string) token {
 		}
 		// -n=10
 		if fName[1] == '=' {
-			nameValue := strings.SplitN(fName, "=", 2)
-			return token{kind: tkFlagWithValue, raw: v, flagName: nameValue[0], flagValue: nameValue[1]}
+			return token{kind: tkFlagWithValue, raw: v, flagName: fName[:1], flagValue: fName[2:]}
 		}
 		// -sSL=bbb
-		if strings.Contains(fName, "=") {
+		if strings.IndexByte(fName, '=') >= 0 {
 			return token{kind: tkInvalid, raw: v}
 		}
 		// -sSL
@@ -269,7 +348,12 @@ func (l *lexer) toToken(v string) token {
 }
 
 func (l *lexer) readAllAsLiteral() []string {
-	remain := l.args[l.current:]
+	remain := make([]string, 0, len(l.args)-l.current)
+	for _, arg := range l.args[l.current:] {
+		if arg != "" {
+			remain = append(remain, arg)
+		}
+	}
 	l.current = len(l.args)
 	return remain
 }
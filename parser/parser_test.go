@@ -1,13 +1,21 @@
 package parser_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/ymgyt/cli/parser"
 )
 
+// ignorePosition ignores Index and Path of parser.Flag for tests which check only names and values.
+var ignorePosition = cmpopts.IgnoreFields(parser.Flag{}, "Index", "Path") // nolint: gochecknoglobals
+
 func TestNew(t *testing.T) {
 	p := parser.New(&fakeCmd{})
 	if p == nil {
@@ -19,6 +27,8 @@ type fakeCmd struct {
 	name      string
 	subs      []*fakeCmd
 	boolFlags []string
+	flags     []string
+	mode      parser.ArgsMode
 }
 
 func (f *fakeCmd) Name() string { return f.name }
@@ -41,6 +51,17 @@ func (f *fakeCmd) IsBoolFlag(name string) bool {
 	return false
 }
 
+func (f *fakeCmd) HasFlag(name string) bool {
+	for _, flag := range f.flags {
+		if name == flag {
+			return true
+		}
+	}
+	return f.IsBoolFlag(name)
+}
+
+func (f *fakeCmd) ArgsMode() parser.ArgsMode { return f.mode }
+
 func TestParser_Parse(t *testing.T) {
 
 	type checkFn func(*testing.T, *parser.Result, error)
@@ -71,7 +92,7 @@ func TestParser_Parse(t *testing.T) {
 	hasFlags := func(flags ...*parser.Flag) checkFn {
 		return func(t *testing.T, r *parser.Result, err error) {
 			notNil(t, r)
-			if diff := cmp.Diff(r.AllFlags(), flags); diff != "" {
+			if diff := cmp.Diff(r.AllFlags(), flags, ignorePosition); diff != "" {
 				t.Errorf("flags does not match. (-got +want)%s", diff)
 			}
 		}
@@ -80,7 +101,7 @@ func TestParser_Parse(t *testing.T) {
 		return func(t *testing.T, r *parser.Result, err error) {
 			notNil(t, r)
 			got := r.Flags(cmd)
-			if diff := cmp.Diff(got, flags); diff != "" {
+			if diff := cmp.Diff(got, flags, ignorePosition); diff != "" {
 				t.Errorf("flags of %q does not match. (-got +want)%s", cmd, diff)
 			}
 		}
@@ -209,9 +230,235 @@ func TestParser_Parse(t *testing.T) {
 	}
 }
 
+func TestParser_Parse_order(t *testing.T) {
+	root := &fakeCmd{
+		name: "root",
+		subs: []*fakeCmd{
+			{name: "sub", subs: []*fakeCmd{{name: "sub", boolFlags: []string{"a", "b"}}}},
+		},
+	}
+	args := []string{"--outs", "1", "sub", "", "--outs=2", "sub", "-ab", "--outs", "3"}
+	r, err := parser.New(root).Parse(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*parser.Flag{
+		{Name: "outs", Value: "1", Index: 0, Path: "root"},
+		{Name: "outs", Value: "2", Index: 4, Path: "root sub"},
+		{Name: "a", IsBool: true, BoolValue: true, Index: 6, Path: "root sub sub"},
+		{Name: "b", IsBool: true, BoolValue: true, Index: 6, Path: "root sub sub"},
+		{Name: "outs", Value: "3", Index: 7, Path: "root sub sub"},
+	}
+	for i := 0; i < 10; i++ {
+		if diff := cmp.Diff(r.AllFlags(), want); diff != "" {
+			t.Fatalf("flags does not match. (-got +want)%s", diff)
+		}
+	}
+	if diff := cmp.Diff(r.FlagsByPath("root sub"), want[1:2]); diff != "" {
+		t.Errorf("flags of path does not match. (-got +want)%s", diff)
+	}
+	if diff := cmp.Diff(r.FlagsByPath("root sub sub"), want[2:]); diff != "" {
+		t.Errorf("flags of path does not match. (-got +want)%s", diff)
+	}
+}
+
+func TestParser_Parse_argsMode(t *testing.T) {
+	build := func(mode parser.ArgsMode) *fakeCmd {
+		return &fakeCmd{
+			name:      "app",
+			boolFlags: []string{"v"},
+			flags:     []string{"log"},
+			subs: []*fakeCmd{
+				{name: "exec", boolFlags: []string{"d"}, flags: []string{"env"}, mode: mode},
+			},
+		}
+	}
+	args := []string{"-v", "exec", "--env=dev", "mycmd", "--flag", "-d", "--", "x", "-z"}
+	if _, err := parser.New(build(parser.Interspersed)).Parse(args); err == nil {
+		t.Error("interspersed: --flag should take -d as value and fail")
+	}
+	tests := map[string]struct {
+		mode      parser.ArgsMode
+		wantArgs  []string
+		wantFlags []*parser.Flag
+	}{
+		"stop at first arg": {
+			mode:     parser.StopAtFirstArg,
+			wantArgs: []string{"mycmd", "--flag", "-d", "--", "x", "-z"},
+			wantFlags: []*parser.Flag{
+				{Name: "v", IsBool: true, BoolValue: true},
+				{Name: "env", Value: "dev"},
+			},
+		},
+		"pass through unknown flags": {
+			mode:     parser.PassThroughUnknownFlags,
+			wantArgs: []string{"mycmd", "--flag", "x", "-z"},
+			wantFlags: []*parser.Flag{
+				{Name: "v", IsBool: true, BoolValue: true},
+				{Name: "env", Value: "dev"},
+				{Name: "d", IsBool: true, BoolValue: true},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := parser.New(build(tc.mode)).Parse(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(r.Args(), tc.wantArgs); diff != "" {
+				t.Errorf("args does not match. (-got +want)%s", diff)
+			}
+			if diff := cmp.Diff(r.AllFlags(), tc.wantFlags, ignorePosition); diff != "" {
+				t.Errorf("flags does not match. (-got +want)%s", diff)
+			}
+		})
+	}
+
+	t.Run("no sub command after pass through", func(t *testing.T) {
+		cmd := build(parser.PassThroughUnknownFlags)
+		cmd.subs[0].subs = []*fakeCmd{{name: "inner"}}
+		for _, args := range [][]string{
+			{"exec", "--foo", "inner", "x"},
+			{"exec", "--foo=val", "inner", "x"},
+		} {
+			r, err := parser.New(cmd).Parse(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(r.Commands(), []string{"exec"}); diff != "" {
+				t.Errorf("%v: commands does not match. (-got +want)%s", args, diff)
+			}
+			if diff := cmp.Diff(r.Args(), args[1:]); diff != "" {
+				t.Errorf("%v: args does not match. (-got +want)%s", args, diff)
+			}
+		}
+	})
+}
+
 func TestError_Error(t *testing.T) {
 	err := &parser.Error{Flag: "label", Msg: "message"}
 	if err.Error() == "" {
 		t.Fatal("empty error message")
 	}
 }
+
+func TestParser_Parse_responseFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	args := write("args", `# comment line
+--label
+app
+
+"  spaced  "
+'@literal'
+@nested
+`)
+	write("nested", "--verbose\n\"a\\tb\"\n")
+	cycle := write("cycle", "@cycle2\n")
+	write("cycle2", "@cycle\n")
+
+	root := &fakeCmd{name: "root", boolFlags: []string{"verbose"}}
+
+	t.Run("expand", func(t *testing.T) {
+		p := &parser.Parser{Root: root, ResponseFiles: true}
+		r, err := p.Parse([]string{"@" + args, "@@escaped", "--", "@" + args})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(r.Args(), []string{"  spaced  ", "@literal", "a\tb", "@escaped", "@" + args}); diff != "" {
+			t.Errorf("args does not match. (-got +want)%s", diff)
+		}
+		wantFlags := []*parser.Flag{{Name: "label", Value: "app"}, {Name: "verbose", IsBool: true, BoolValue: true}}
+		if diff := cmp.Diff(r.AllFlags(), wantFlags, ignorePosition); diff != "" {
+			t.Errorf("flags does not match. (-got +want)%s", diff)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		r, err := (&parser.Parser{Root: root}).Parse([]string{"@" + args})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(r.Args(), []string{"@" + args}); diff != "" {
+			t.Errorf("args does not match. (-got +want)%s", diff)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		_, err := (&parser.Parser{Root: root, ResponseFiles: true}).Parse([]string{"@" + cycle})
+		if _, ok := err.(*parser.Error); !ok {
+			t.Errorf("want *parser.Error, got %v", err)
+		}
+	})
+
+	t.Run("not exists", func(t *testing.T) {
+		_, err := (&parser.Parser{Root: root, ResponseFiles: true}).Parse([]string{"@" + filepath.Join(dir, "none")})
+		if _, ok := err.(*parser.Error); !ok {
+			t.Errorf("want *parser.Error, got %v", err)
+		}
+	})
+
+	t.Run("unterminated quote", func(t *testing.T) {
+		path := write("unterminated", "\"abc\n")
+		_, err := (&parser.Parser{Root: root, ResponseFiles: true}).Parse([]string{"@" + path})
+		if _, ok := err.(*parser.Error); !ok {
+			t.Errorf("want *parser.Error, got %v", err)
+		}
+	})
+}
+
+func hugeArgs(n int) []string {
+	args := make([]string, 0, n+3)
+	args = append(args, "sub", "--label=app", "-v")
+	for i := 0; i < n; i++ {
+		args = append(args, "file"+strconv.Itoa(i)+".go")
+	}
+	return args
+}
+
+func TestParser_Parse_hugeArgs(t *testing.T) {
+	root := &fakeCmd{name: "root", subs: []*fakeCmd{{name: "sub", boolFlags: []string{"v"}}}}
+	p := parser.New(root)
+	for _, n := range []int{1000, 100000} {
+		args := hugeArgs(n)
+		allocs := testing.AllocsPerRun(5, func() {
+			r, err := p.Parse(args)
+			if err != nil || len(r.Args()) != n {
+				t.Fatalf("Parse() %v, %d args", err, len(r.Args()))
+			}
+		})
+		// allocations should not depend on the number of arguments.
+		if allocs > 30 {
+			t.Errorf("%d args: %v allocations per Parse", n, allocs)
+		}
+	}
+}
+
+func BenchmarkParser_Parse(b *testing.B) {
+	root := &fakeCmd{name: "root", subs: []*fakeCmd{{name: "sub", boolFlags: []string{"v"}}}}
+	p := parser.New(root)
+	for _, n := range []int{1000, 10000, 100000} {
+		args := hugeArgs(n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := p.Parse(args); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
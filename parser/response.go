@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+const responseFilePrefix = "@"
+
+// expandResponseFiles replace "@path" arguments with the arguments read from path.
+// response file contains one argument per line. empty lines and lines starting with '#' are ignored.
+// a line can be quoted by single or double quote to keep surrounding spaces.
+// "@@foo" is passed as literal "@foo". after "--", nothing is expanded.
+func expandResponseFiles(args []string) ([]string, error) {
+	e := &responseExpander{}
+	if err := e.expand(args, ""); err != nil {
+		return nil, err
+	}
+	return e.args, nil
+}
+
+type responseExpander struct {
+	args       []string
+	stack      []string
+	terminated bool
+}
+
+func (e *responseExpander) expand(args []string, dir string) error {
+	for _, arg := range args {
+		if e.terminated || !strings.HasPrefix(arg, responseFilePrefix) || len(arg) == 1 {
+			e.add(arg)
+			continue
+		}
+		if strings.HasPrefix(arg, responseFilePrefix+responseFilePrefix) {
+			e.add(arg[1:])
+			continue
+		}
+		if err := e.expandFile(arg[1:], dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *responseExpander) add(arg string) {
+	if arg == "--" {
+		e.terminated = true
+	}
+	e.args = append(e.args, arg)
+}
+
+func (e *responseExpander) expandFile(path, dir string) error {
+	if !filepath.IsAbs(path) && dir != "" {
+		path = filepath.Join(dir, path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return &Error{Kind: KindResponseFile, Flag: responseFilePrefix + path, Msg: err.Error()}
+	}
+	for _, included := range e.stack {
+		if included == abs {
+			return &Error{Kind: KindResponseFile, Flag: responseFilePrefix + path, Msg: fmt.Sprintf("response file cycle detected (%s)", strings.Join(append(e.stack, abs), " -> "))}
+		}
+	}
+
+	b, err := ioutil.ReadFile(abs)
+	if err != nil {
+		return &Error{Kind: KindResponseFile, Flag: responseFilePrefix + path, Msg: err.Error()}
+	}
+
+	e.stack = append(e.stack, abs)
+	defer func() { e.stack = e.stack[:len(e.stack)-1] }()
+
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		arg, quoted, err := unquoteLine(line)
+		if err != nil {
+			return &Error{Kind: KindResponseFile, Flag: responseFilePrefix + path, Msg: fmt.Sprintf("line %d: %s", n, err)}
+		}
+		if quoted {
+			e.add(arg)
+			continue
+		}
+		if err := e.expand([]string{arg}, filepath.Dir(abs)); err != nil {
+			return err
+		}
+	}
+	if err := s.Err(); err != nil {
+		return &Error{Kind: KindResponseFile, Flag: responseFilePrefix + path, Msg: err.Error()}
+	}
+	return nil
+}
+
+// unquoteLine strip quotes of a response file line.
+// single quoted line is taken literally, double quoted one interprets backslash escapes.
+func unquoteLine(line string) (arg string, quoted bool, err error) {
+	q := line[0]
+	if q != '\'' && q != '"' {
+		return line, false, nil
+	}
+	if len(line) < 2 || line[len(line)-1] != q {
+		return "", false, fmt.Errorf("unterminated quote %s", line)
+	}
+	body := line[1 : len(line)-1]
+	if q == '\'' {
+		return body, true, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(body) {
+			return "", false, fmt.Errorf("unterminated escape %s", line)
+		}
+		i++
+		switch body[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			b.WriteByte(body[i])
+		}
+	}
+	return b.String(), true, nil
+}
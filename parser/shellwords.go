@@ -0,0 +1,188 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnterminatedQuote  = errors.New("unterminated quote")
+	ErrUnterminatedEscape = errors.New("unterminated escape")
+	ErrInvalidVariable    = errors.New("invalid variable")
+)
+
+// ShellWords splits a command string into words like POSIX shell.
+// single quotes, double quotes, backslash escapes and "#" comments are supported.
+type ShellWords struct {
+	// Expand returns value of "$VAR" and "${VAR}". if nil, "$" is taken literally.
+	// expanded value is not split into words. unquoted word which expands to empty is removed.
+	Expand func(name string) string
+}
+
+// SplitWords splits s into words without variable expansion.
+func SplitWords(s string) ([]string, error) {
+	return (&ShellWords{}).Split(s)
+}
+
+func (sw *ShellWords) Split(s string) ([]string, error) {
+	var (
+		words  []string
+		word   strings.Builder
+		inWord bool
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '#' && !inWord:
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '\\':
+			if i+1 >= len(s) {
+				return nil, fmt.Errorf("%w at %d", ErrUnterminatedEscape, i)
+			}
+			i++
+			// backslash newline continues the line.
+			if s[i] != '\n' {
+				word.WriteByte(s[i])
+				inWord = true
+			}
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("%w at %d", ErrUnterminatedQuote, i)
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			next, err := sw.doubleQuoted(s, i, &word)
+			if err != nil {
+				return nil, err
+			}
+			i = next
+			inWord = true
+		case c == '$' && sw.Expand != nil:
+			next, err := sw.variable(s, i, &word)
+			if err != nil {
+				return nil, err
+			}
+			i = next
+			// unquoted empty expansion does not make a word as shell does.
+			inWord = inWord || word.Len() > 0
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// doubleQuoted write the content of double quote starting at s[start] and return index of closing quote.
+// backslash escapes only '$', '`', '"', '\' and newline as shell does.
+func (sw *ShellWords) doubleQuoted(s string, start int, word *strings.Builder) (int, error) {
+	for i := start + 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			return i, nil
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("$`\"\\\n", s[i+1]) >= 0:
+			i++
+			if s[i] != '\n' {
+				word.WriteByte(s[i])
+			}
+		case c == '$' && sw.Expand != nil:
+			next, err := sw.variable(s, i, word)
+			if err != nil {
+				return 0, err
+			}
+			i = next
+		default:
+			word.WriteByte(c)
+		}
+	}
+	return 0, fmt.Errorf("%w at %d", ErrUnterminatedQuote, start)
+}
+
+// variable write the value of variable starting at s[start] and return index of its last byte.
+// "$" not followed by a name is taken literally.
+func (sw *ShellWords) variable(s string, start int, word *strings.Builder) (int, error) {
+	if start+1 < len(s) && s[start+1] == '{' {
+		end := strings.IndexByte(s[start+2:], '}')
+		if end < 0 {
+			return 0, fmt.Errorf("%w at %d: missing }", ErrInvalidVariable, start)
+		}
+		name := s[start+2 : start+2+end]
+		if !isVariableName(name) {
+			return 0, fmt.Errorf("%w at %d: %q", ErrInvalidVariable, start, name)
+		}
+		word.WriteString(sw.Expand(name))
+		return start + 2 + end, nil
+	}
+	end := start + 1
+	for end < len(s) && isVariableByte(s[end], end == start+1) {
+		end++
+	}
+	if end == start+1 {
+		word.WriteByte('$')
+		return start, nil
+	}
+	word.WriteString(sw.Expand(s[start+1 : end]))
+	return end - 1, nil
+}
+
+func isVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isVariableByte(name[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isVariableByte(c byte, first bool) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || !first && '0' <= c && c <= '9'
+}
+
+// Quote return s quoted to be read as a single word by shell and SplitWords.
+func Quote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for i := 0; i < len(s); i++ {
+		if !isSafeByte(s[i]) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// QuoteWords quote each word and join them with space. SplitWords(QuoteWords(words)) returns words.
+func QuoteWords(words []string) string {
+	quoted := make([]string, 0, len(words))
+	for _, w := range words {
+		quoted = append(quoted, Quote(w))
+	}
+	return strings.Join(quoted, " ")
+}
+
+func isSafeByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("_-+=@%:,./", c) >= 0
+}
@@ -0,0 +1,86 @@
+package parser_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli/parser"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  []string
+	}{
+		"plain":           {input: "sub  -s\tx\n--v", want: []string{"sub", "-s", "x", "--v"}},
+		"double quote":    {input: `sub -s "match all"`, want: []string{"sub", "-s", "match all"}},
+		"single quote":    {input: `--outs='a b'`, want: []string{"--outs=a b"}},
+		"empty quote":     {input: `a '' ""`, want: []string{"a", "", ""}},
+		"backslash":       {input: `a\ b c\"d`, want: []string{"a b", `c"d`}},
+		"escape in dq":    {input: `"a\"b\\c\d"`, want: []string{`a"b\c\d`}},
+		"no escape in sq": {input: `'a\b'`, want: []string{`a\b`}},
+		"concat":          {input: `a"b c"'d'`, want: []string{"ab cd"}},
+		"comment":         {input: "a # comment\nb c#d", want: []string{"a", "b", "c#d"}},
+		"continuation":    {input: "a \\\nb", want: []string{"a", "b"}},
+		"dollar literal":  {input: "$HOME", want: []string{"$HOME"}},
+		"empty":           {input: "  ", want: nil},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parser.SplitWords(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("(-got +want)%s", diff)
+			}
+		})
+	}
+}
+
+func TestSplitWords_error(t *testing.T) {
+	tests := map[string]error{
+		`a "b`:   parser.ErrUnterminatedQuote,
+		`a 'b`:   parser.ErrUnterminatedQuote,
+		`a b\`:   parser.ErrUnterminatedEscape,
+		`${HOME`: parser.ErrInvalidVariable,
+	}
+	sw := &parser.ShellWords{Expand: func(string) string { return "" }}
+	for input, want := range tests {
+		if _, err := sw.Split(input); !errors.Is(err, want) {
+			t.Errorf("%s: got %v, want %v", input, err, want)
+		}
+	}
+}
+
+func TestShellWords_Split_expand(t *testing.T) {
+	env := map[string]string{"HOME": "/home/gopher", "NAME": "a b"}
+	sw := &parser.ShellWords{Expand: func(name string) string { return env[name] }}
+	got, err := sw.Split(`cd $HOME/src "${NAME}x" '$HOME' \$HOME $ $1 $UNDEFINED x$UNDEFINED "$UNDEFINED" ''${UNDEFINED}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"cd", "/home/gopher/src", "a bx", "$HOME", "$HOME", "$", "$1", "x", "", ""}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+}
+
+func TestQuoteWords(t *testing.T) {
+	words := []string{"sub", "-s", "match all", "--outs=a b", "it's", "", `a"b\c`, "$HOME", "#x", "a\nb"}
+	quoted := parser.QuoteWords(words)
+	want := `sub -s 'match all' '--outs=a b' 'it'\''s' '' 'a"b\c' '$HOME' '#x' 'a
+b'`
+	if diff := cmp.Diff(quoted, want); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+	got, err := parser.SplitWords(quoted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, words); diff != "" {
+		t.Errorf("round trip (-got +want)%s", diff)
+	}
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"unicode"
+
+	"github.com/ymgyt/cli/flags"
+)
+
+// Plugin is an external sub command "<command path>-<name>" like "app-foo".
+type Plugin struct {
+	Name string
+	Path string
+}
+
+// ListPlugins return plugins found in PluginDirs and $PATH sorted by name.
+// if same name plugins exist, former one wins. plugins shadowed by sub commands are excluded.
+func (c *Command) ListPlugins() []*Plugin {
+	if !c.EnablePlugins {
+		return nil
+	}
+	prefix := c.pluginPrefix()
+	found := make(map[string]*Plugin)
+	var plugins []*Plugin
+	for _, dir := range c.pluginDirs() {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fi := range files {
+			name := strings.TrimPrefix(fi.Name(), prefix)
+			if name == fi.Name() || name == "" || found[name] != nil || !isExecutable(fi) || c.Lookup(name) != nil {
+				continue
+			}
+			p := &Plugin{Name: name, Path: filepath.Join(dir, fi.Name())}
+			found[name] = p
+			plugins = append(plugins, p)
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// LookupPlugin return the plugin of name.
+func (c *Command) LookupPlugin(name string) (*Plugin, bool) {
+	if !c.EnablePlugins || name == "" || strings.ContainsRune(name, filepath.Separator) || c.Lookup(name) != nil {
+		return nil, false
+	}
+	for _, dir := range c.pluginDirs() {
+		path := filepath.Join(dir, c.pluginPrefix()+name)
+		if fi, err := os.Stat(path); err == nil && isExecutable(fi) {
+			return &Plugin{Name: name, Path: path}, true
+		}
+	}
+	return nil, false
+}
+
+func (c *Command) pluginPrefix() string {
+	return strings.Replace(c.Path(), " ", "-", -1) + "-"
+}
+
+// pluginDirs return absolute paths of PluginDirs and $PATH so that plugin path is never searched in $PATH again.
+// empty and relative entries of $PATH are skipped.
+func (c *Command) pluginDirs() []string {
+	dirs := make([]string, 0, len(c.PluginDirs))
+	for _, dir := range c.PluginDirs {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dirs = append(dirs, abs)
+		}
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func isExecutable(fi os.FileInfo) bool {
+	return fi.Mode().IsRegular() && fi.Mode().Perm()&0111 != 0
+}
+
+// runPlugin execute plugin with args and return its exit code. if plugin is killed by signal, 128 + signal number is returned.
+// resolved values of flags of c are exported as "<ROOT>_<FLAG>" environment variables like "MY_APP_LOG_LEVEL" for "my-app --log-level".
+func (c *Command) runPlugin(ctx context.Context, p *Plugin, args []string) int {
+	cmd := exec.CommandContext(ctx, p.Path, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = c.Stdin, c.Stdout, c.Stderr
+	cmd.Env = append(os.Environ(), c.pluginEnv()...)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr):
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			return 128 + int(ws.Signal())
+		}
+		return exitErr.ExitCode()
+	default:
+		fmt.Fprintf(c.Stderr, "plugin %s: %s\n", p.Name, err)
+		return 1
+	}
+}
+
+// pluginEnv return environment variables of flags. secret, hidden and built-in flags are not exported.
+func (c *Command) pluginEnv() []string {
+	prefix := envName(c.root().Name) + "_"
+	var env []string
+	c.traverseFlags(func(f *flags.Flag) {
+		// deprecated flags share Var with their replacements and file flags set their targets.
+		if _, isFile := f.Var.(*flags.FileVar); isFile || f.ReplacedBy != "" {
+			return
+		}
+		if f.Secret || f.Hidden || c.isBuiltinFlag(f) {
+			return
+		}
+		env = append(env, prefix+envName(f.Name())+"="+f.Value())
+	})
+	return env
+}
+
+// isBuiltinFlag reports whether f is added by the command tree itself like "--version".
+func (c *Command) isBuiltinFlag(f *flags.Flag) bool {
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if f.Var == flags.Var((*flags.BoolVar)(&cmd.showVersion)) {
+			return true
+		}
+	}
+	return false
+}
+
+// envName convert s to upper case and replace characters other than [A-Z0-9_] with "_".
+func envName(s string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToUpper(r)
+		if ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
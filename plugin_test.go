@@ -0,0 +1,152 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli"
+)
+
+func TestCommand_Plugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	dir, err := ioutil.TempDir("", "plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string, perm os.FileMode) {
+		t.Helper()
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("clitest-hello", `#!/bin/sh
+read line
+echo "args:$* env:$CLITEST_LOG_LEVEL,$CLITEST_RETRY,$CLITEST_OUTS stdin:$line"
+exit 3
+`, 0755)
+	write("clitest-ok", "#!/bin/sh\nexit 0\n", 0755)
+	write("clitest-killed", "#!/bin/sh\nkill -TERM $$\n", 0755)
+	write("clitest-version", "#!/bin/sh\necho shadowed\n", 0755)
+	write("clitest-noexec", "#!/bin/sh\n", 0644)
+
+	build := func(stdin string, stdout *bytes.Buffer, code *int) *cli.Command {
+		var level string
+		var retry int
+		var outs []string
+		root := &cli.Command{
+			Name:          "clitest",
+			EnablePlugins: true,
+			PluginDirs:    []string{dir},
+			Stdin:         strings.NewReader(stdin),
+			Stdout:        stdout,
+			Stderr:        stdout,
+			Exit:          func(c int) { *code = c },
+		}
+		root.PersistentOptions().
+			Add(&cli.StringOpt{Var: &level, Long: "log-level"}).
+			Add(&cli.IntOpt{Var: &retry, Long: "retry", Default: 2}).
+			Add(&cli.StringsOpt{Var: &outs, Long: "outs"})
+		return root.AddCommand(&cli.Command{Name: "version", Stdout: stdout, Run: func(_ context.Context, cmd *cli.Command, _ []string) {
+			cmd.Stdout.Write([]byte("v1\n"))
+		}})
+	}
+
+	t.Run("list", func(t *testing.T) {
+		var got []string
+		for _, p := range build("", &bytes.Buffer{}, new(int)).ListPlugins() {
+			got = append(got, p.Name)
+		}
+		if diff := cmp.Diff(got, []string{"hello", "killed", "ok"}); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+	})
+
+	t.Run("execute", func(t *testing.T) {
+		var stdout bytes.Buffer
+		code := -1
+		build("input\n", &stdout, &code).ExecuteWithArgs(context.Background(), []string{"--log-level=debug", "--outs", "a", "--outs", "b", "hello", "--name", "x"})
+		if diff := cmp.Diff(stdout.String(), "args:--name x env:debug,2,a,b stdin:input\n"); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+		if code != 3 {
+			t.Errorf("exit code got %d, want 3", code)
+		}
+	})
+
+	t.Run("success does not exit", func(t *testing.T) {
+		code := -1
+		build("", &bytes.Buffer{}, &code).ExecuteWithArgs(context.Background(), []string{"ok"})
+		if code != -1 {
+			t.Errorf("Exit should not be called, got %d", code)
+		}
+	})
+
+	t.Run("killed by signal", func(t *testing.T) {
+		code := -1
+		build("", &bytes.Buffer{}, &code).ExecuteWithArgs(context.Background(), []string{"killed"})
+		if code != 143 {
+			t.Errorf("exit code got %d, want 143", code)
+		}
+	})
+
+	t.Run("sub command wins", func(t *testing.T) {
+		var stdout bytes.Buffer
+		build("", &stdout, new(int)).ExecuteWithArgs(context.Background(), []string{"version"})
+		if stdout.String() != "v1\n" {
+			t.Errorf("got %q", stdout.String())
+		}
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		write("my-app-env", "#!/bin/sh\nenv | grep '^MY_APP_' | sort\n", 0755)
+		var stdout bytes.Buffer
+		var level, token string
+		var trace bool
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel, err := filepath.Rel(wd, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root := &cli.Command{Name: "my-app", EnablePlugins: true, PluginDirs: []string{rel}, VersionFlag: true, Stdout: &stdout, Stderr: &stdout}
+		root.PersistentOptions().
+			Add(&cli.StringOpt{Var: &level, Long: "log-level"}).
+			Add(&cli.StringOpt{Var: &token, Long: "token", Secret: true}).
+			Add(&cli.BoolOpt{Var: &trace, Long: "trace", Hidden: true})
+		if p, ok := root.LookupPlugin("env"); !ok || !filepath.IsAbs(p.Path) {
+			t.Fatalf("plugin path should be absolute, got %+v", p)
+		}
+		root.ExecuteWithArgs(context.Background(), []string{"--log-level=debug", "--token=s3cr3t", "--trace", "env"})
+		if diff := cmp.Diff(stdout.String(), "MY_APP_LOG_LEVEL=debug\n"); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+	})
+
+	t.Run("help and completion", func(t *testing.T) {
+		root := build("", &bytes.Buffer{}, new(int))
+		data := root.HelpData()
+		last := data.CommandGroups[len(data.CommandGroups)-1]
+		if last.Title != "Plugins" || len(last.Commands) != 3 || last.Commands[0].Name != "hello" {
+			t.Errorf("want Plugins group, got %+v", last)
+		}
+		if diff := cmp.Diff(root.Complete([]string{"h"}), []string{"hello"}); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+		if got := root.Complete([]string{"hello", "h"}); len(got) != 0 {
+			t.Errorf("plugins should not be completed as args, got %v", got)
+		}
+	})
+}
@@ -5,8 +5,11 @@ import (
 	"errors"
 	"io"
 	"os"
+	"os/signal"
 	"strings"
 	"time"
+
+	"github.com/ymgyt/cli/internal/term"
 )
 
 const (
@@ -16,6 +19,8 @@ const (
 var (
 	defaultTimeout = time.Minute * 30
 	ErrTimeout     = errors.New("timeout")
+	// ErrInterrupted is returned when SIGINT is received while echo is disabled.
+	ErrInterrupted = errors.New("interrupted")
 )
 
 func New() *Session {
@@ -31,6 +36,7 @@ type Session struct {
 	caseInsensitive bool
 	timeout         time.Duration
 	bufferBytes     int
+	noEcho          bool
 	err             error
 }
 
@@ -79,6 +85,13 @@ func (s *Session) SetBufferBytes(n int) *Session {
 	return s
 }
 
+// NoEcho disables echo of user input while reading if reader is a terminal.
+// the terminal is restored when Prompt or Input returns, even by timeout, cancel or SIGINT.
+func (s *Session) NoEcho() *Session {
+	s.noEcho = true
+	return s
+}
+
 func (s *Session) init() {
 	if s.ctx == nil {
 		s.ctx = context.Background()
@@ -102,6 +115,45 @@ func (s *Session) Prompt() (ok bool, err error) {
 	if s.err != nil {
 		return false, err
 	}
+	r := s.wait()
+	if r.err != nil {
+		return false, r.err
+	}
+	return s.handleWord(r.word).ok, nil
+}
+
+// Input display message and return user input line without trailing newline.
+func (s *Session) Input() (string, error) {
+	s.init()
+	if s.err != nil {
+		return "", s.err
+	}
+	r := s.wait()
+	if r.err != nil {
+		return "", r.err
+	}
+	word := r.word
+	if idx := strings.Index(word, "\n"); idx >= 0 {
+		word = word[:idx]
+	}
+	return strings.TrimSuffix(word, "\r"), nil
+}
+
+func (s *Session) wait() result {
+	// echo is restored here rather than in the reader goroutine
+	// because it keeps blocking on Read after timeout or cancel.
+	var interrupt chan os.Signal
+	if s.noEcho {
+		if restore, err := term.DisableEcho(s.in); err == nil {
+			interrupt = make(chan os.Signal, 1)
+			signal.Notify(interrupt, os.Interrupt)
+			defer func() {
+				signal.Stop(interrupt)
+				_ = restore()
+				_, _ = s.out.Write([]byte("\n"))
+			}()
+		}
+	}
 
 	ch := make(chan result, 1)
 	go func() { ch <- s.prompt() }()
@@ -113,13 +165,16 @@ func (s *Session) Prompt() (ok bool, err error) {
 		r = result{err: ErrTimeout}
 	case <-s.ctx.Done():
 		r = result{err: s.ctx.Err()}
+	case <-interrupt:
+		r = result{err: ErrInterrupted}
 	}
-	return r.ok, r.err
+	return r
 }
 
 type result struct {
-	ok  bool
-	err error
+	ok   bool
+	word string
+	err  error
 }
 
 func (s *Session) prompt() result {
@@ -154,7 +209,7 @@ func (s *Session) prompt() result {
 			break
 		}
 	}
-	return s.handleWord(word)
+	return result{word: word}
 }
 
 func (s *Session) handleWord(word string) result {
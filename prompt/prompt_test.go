@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"io/ioutil"
+	"strings"
 	"testing"
 	"time"
 
@@ -64,6 +65,41 @@ func TestSession_Prompt(t *testing.T) {
 	})
 }
 
+func TestSession_Input(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		in := bytes.NewBufferString("my input\r\nnext line\n")
+		got, err := prompt.New().SetReader(in).SetWriter(ioutil.Discard).Display("input: ").Input()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "my input"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("no echo not terminal", func(t *testing.T) {
+		in := bytes.NewBufferString("s3cr3t\n")
+		out := &bytes.Buffer{}
+		got, err := prompt.New().SetReader(in).SetWriter(out).Display("password: ").NoEcho().Input()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "s3cr3t"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+		if strings.Contains(out.String(), "s3cr3t") {
+			t.Errorf("input should not be written to output, got %q", out.String())
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := prompt.New().SetReader(&custom{}).SetWriter(ioutil.Discard).Timeout(100 * time.Millisecond).Input()
+		if err != prompt.ErrTimeout {
+			t.Fatalf("want timeout error, but got %v", err)
+		}
+	})
+}
+
 type custom struct {
 	i    int
 	buff []byte
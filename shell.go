@@ -0,0 +1,284 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ymgyt/cli/internal/term"
+	"github.com/ymgyt/cli/parser"
+)
+
+// shellBuiltins are commands handled by Shell itself unless sub commands of same name exist.
+var shellBuiltins = []string{"exit", "help", "history"} // nolint: gochecknoglobals
+
+// Shell executes each line read from Root.Stdin as a command line of Root.
+// lines are split by parser.SplitWords. flags are reset before each line.
+// "exit", "help [command...]" and "history" are available as builtins.
+// if Stdin is a terminal, tab completes and up/down arrows recall history.
+type Shell struct {
+	Root *Command
+	// Prompt is displayed before each line if Stdin is a terminal. default is "<root name>> ".
+	Prompt string
+	// History contains lines read in order.
+	History []string
+}
+
+// RunShell run c as an interactive shell until "exit" or EOF.
+func (c *Command) RunShell(ctx context.Context) error {
+	return (&Shell{Root: c}).Run(ctx)
+}
+
+// Run read and execute lines until "exit", EOF or ctx is done.
+func (s *Shell) Run(ctx context.Context) error {
+	root := s.Root
+	root.lasyInit()
+	if s.Prompt == "" {
+		s.Prompt = root.Name + "> "
+	}
+	e := &lineEditor{shell: s, r: root.Stdin, w: root.Stdout, echo: term.IsTerminal(root.Stdin)}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line, err := e.readLine()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			s.History = append(s.History, line)
+			if exit := s.execute(ctx, line); exit {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// execute run the line and reports whether shell should exit.
+func (s *Shell) execute(ctx context.Context, line string) bool {
+	root := s.Root
+	args, err := parser.SplitWords(line)
+	if err != nil {
+		fmt.Fprintf(root.Stderr, "error: %s\n", err)
+		return false
+	}
+	if len(args) == 0 {
+		return false
+	}
+	if root.Lookup(args[0]) == nil {
+		switch args[0] {
+		case "exit":
+			return true
+		case "help":
+			cmd := root
+			for _, name := range args[1:] {
+				if cmd = cmd.Lookup(name); cmd == nil {
+					fmt.Fprintf(root.Stderr, "error: command %s not found\n", strings.Join(args[1:], " "))
+					return false
+				}
+			}
+			cmd.lasyInit()
+			cmd.Help(root.Stdout, cmd)
+			return false
+		case "history":
+			for i, l := range s.History {
+				fmt.Fprintf(root.Stdout, "%4d  %s\n", i+1, l)
+			}
+			return false
+		}
+	}
+	root.Reset()
+	root.ExecuteWithArgs(ctx, args)
+	return false
+}
+
+// Complete return the line whose last word is completed and the candidates.
+// it is called by tab key if Stdin is a terminal.
+func (s *Shell) Complete(line string) (string, []string) {
+	words, err := parser.SplitWords(line)
+	if err != nil {
+		return line, nil
+	}
+	if len(words) == 0 || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+	last := words[len(words)-1]
+	if !strings.HasSuffix(line, last) {
+		return line, nil
+	}
+	candidates := s.Root.Complete(words)
+	if len(words) == 1 {
+		for _, builtin := range shellBuiltins {
+			if strings.HasPrefix(builtin, last) && s.Root.Lookup(builtin) == nil {
+				candidates = append(candidates, builtin)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return line, nil
+	}
+	completed := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		completed += " "
+	}
+	return line[:len(line)-len(last)] + completed, candidates
+}
+
+func commonPrefix(ss []string) string {
+	prefix := ss[0]
+	for _, s := range ss[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+const (
+	keyCtrlC     = 0x03
+	keyCtrlD     = 0x04
+	keyBackspace = 0x08
+	keyTab       = '\t'
+	keyEscape    = 0x1b
+	keyDelete    = 0x7f
+)
+
+// lineEditor reads a line byte by byte to handle completion and history.
+// it does not buffer input so that commands can read the rest of stdin.
+type lineEditor struct {
+	shell *Shell
+	r     io.Reader
+	w     io.Writer
+	// echo writes input back and enables line editing. terminal is in raw mode only while reading a line with echo,
+	// so that commands run with signal keys, echo and line editing of the terminal.
+	echo bool
+	line []byte
+	// recall is an index of History displayed by arrow keys.
+	recall int
+	buf    [1]byte
+}
+
+func (e *lineEditor) readLine() (string, error) {
+	if !e.echo {
+		return e.readRawLine()
+	}
+	if restore, err := term.MakeRaw(e.r); err == nil {
+		defer restore() // nolint: errcheck
+	}
+	e.line = e.line[:0]
+	e.recall = len(e.shell.History)
+	e.print(e.shell.Prompt)
+	for {
+		b, err := e.readByte()
+		if err != nil {
+			return string(e.line), err
+		}
+		switch b {
+		case '\r', '\n':
+			e.print("\r\n")
+			return string(e.line), nil
+		case keyCtrlD:
+			if len(e.line) == 0 {
+				e.print("\r\n")
+				return "", io.EOF
+			}
+		case keyCtrlC:
+			e.line = e.line[:0]
+			e.print("^C\r\n" + e.shell.Prompt)
+		case keyBackspace, keyDelete:
+			if len(e.line) > 0 {
+				_, size := utf8.DecodeLastRune(e.line)
+				e.line = e.line[:len(e.line)-size]
+				e.print("\b \b")
+			}
+		case keyTab:
+			completed, candidates := e.shell.Complete(string(e.line))
+			if len(candidates) > 1 {
+				e.print("\r\n" + strings.Join(candidates, "  ") + "\r\n")
+				e.line = append(e.line[:0], completed...)
+				e.redraw()
+			} else {
+				e.replace(completed)
+			}
+		case keyEscape:
+			e.escape()
+		default:
+			if b >= 0x20 {
+				e.line = append(e.line, b)
+				e.print(string(e.line[len(e.line)-1:]))
+			}
+		}
+	}
+}
+
+// readRawLine reads a line which is not typed in a terminal like piped input.
+// bytes other than "\n" and "\r\n" are taken as they are.
+func (e *lineEditor) readRawLine() (string, error) {
+	e.line = e.line[:0]
+	for {
+		b, err := e.readByte()
+		if err != nil {
+			return string(e.line), err
+		}
+		if b == '\n' {
+			return strings.TrimSuffix(string(e.line), "\r"), nil
+		}
+		e.line = append(e.line, b)
+	}
+}
+
+// escape handles up and down arrow keys "ESC [ A" and "ESC [ B".
+func (e *lineEditor) escape() {
+	if b, err := e.readByte(); err != nil || b != '[' {
+		return
+	}
+	b, err := e.readByte()
+	if err != nil {
+		return
+	}
+	history := e.shell.History
+	switch {
+	case b == 'A' && e.recall > 0:
+		e.recall--
+		e.replace(history[e.recall])
+	case b == 'B' && e.recall < len(history)-1:
+		e.recall++
+		e.replace(history[e.recall])
+	case b == 'B' && e.recall == len(history)-1:
+		e.recall++
+		e.replace("")
+	}
+}
+
+func (e *lineEditor) readByte() (byte, error) {
+	for {
+		n, err := e.r.Read(e.buf[:])
+		if n == 1 {
+			return e.buf[0], nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (e *lineEditor) replace(line string) {
+	e.line = append(e.line[:0], line...)
+	e.redraw()
+}
+
+func (e *lineEditor) redraw() {
+	e.print("\r\x1b[K" + e.shell.Prompt + string(e.line))
+}
+
+func (e *lineEditor) print(s string) {
+	if e.echo {
+		fmt.Fprint(e.w, s)
+	}
+}
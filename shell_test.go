@@ -0,0 +1,129 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli"
+)
+
+func TestShell_Run(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	var executed []string
+	var level int
+	var labels []string
+	root := &cli.Command{Name: "app", Stdout: &stdout, Stderr: &stderr}
+	get := &cli.Command{Name: "get", ShortDesc: "get resources"}
+	pod := &cli.Command{Name: "pod", Run: func(_ context.Context, _ *cli.Command, args []string) {
+		executed = append(executed, fmt.Sprintf("level=%d labels=%v args=%v", level, labels, args))
+	}}
+	pod.Options().
+		Add(&cli.IntOpt{Var: &level, Long: "level", Short: "l"}).
+		Add(&cli.StringsOpt{Var: &labels, Long: "label"})
+	root.AddCommand(get.AddCommand(pod))
+
+	input := strings.Join([]string{
+		`get pod -l 2 --label=x "a b"`,
+		`get pod --label=y`,
+		"get\tpod\t--level\t4 c\r",
+		`get "unterminated`,
+		"history",
+		"help get",
+		"exit",
+		"get pod --level=5",
+	}, "\n")
+	root.Stdin = strings.NewReader(input)
+	shell := &cli.Shell{Root: root}
+	if err := shell.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// flag values should not leak to next line.
+	want := []string{"level=2 labels=[x] args=[a b]", "level=0 labels=[y] args=[]", "level=4 labels=[] args=[c]"}
+	if diff := cmp.Diff(executed, want); diff != "" {
+		t.Errorf("executed (-got +want)%s", diff)
+	}
+	wantHistory := "   1  get pod -l 2 --label=x \"a b\"\n   2  get pod --label=y\n   3  get\tpod\t--level\t4 c\n   4  get \"unterminated\n   5  history\n"
+	if !strings.HasPrefix(stdout.String(), wantHistory) {
+		t.Errorf("history got %q", stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "Usage:\n  app get <command>") {
+		t.Errorf("help of get should be printed, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "unterminated quote") {
+		t.Errorf("split error should be printed, got %q", stderr.String())
+	}
+}
+
+func TestShell_Run_pipedTab(t *testing.T) {
+	var got [][]string
+	root := &cli.Command{Name: "app", Stdin: strings.NewReader("echo a\tb \"x\ty\"\n"), Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+	root.AddCommand(&cli.Command{Name: "echo", Run: func(_ context.Context, _ *cli.Command, args []string) {
+		got = append(got, args)
+	}})
+	if err := (&cli.Shell{Root: root}).Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// tab of piped input is not a completion key.
+	if diff := cmp.Diff(got, [][]string{{"a", "b", "x\ty"}}); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+}
+
+func TestShell_Complete(t *testing.T) {
+	root := &cli.Command{Name: "app"}
+	get := &cli.Command{Name: "get"}
+	var level int
+	pod := &cli.Command{Name: "pod", Run: func(_ context.Context, _ *cli.Command, _ []string) {}}
+	pod.Options().Add(&cli.IntOpt{Var: &level, Long: "level"})
+	root.AddCommand(get.AddCommand(pod)).AddCommand(&cli.Command{Name: "health"})
+	shell := &cli.Shell{Root: root}
+
+	tests := map[string]struct {
+		line           string
+		want           string
+		wantCandidates []string
+	}{
+		"command":  {line: "ge", want: "get ", wantCandidates: []string{"get"}},
+		"flag":     {line: "get pod --le", want: "get pod --level ", wantCandidates: []string{"--level"}},
+		"builtins": {line: "h", want: "h", wantCandidates: []string{"health", "help", "history"}},
+		"none":     {line: "x", want: "x"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, candidates := shell.Complete(tc.line)
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+			if diff := cmp.Diff(candidates, tc.wantCandidates); diff != "" {
+				t.Errorf("candidates (-got +want)%s", diff)
+			}
+		})
+	}
+}
+
+func TestShell_Run_stdin(t *testing.T) {
+	var got []string
+	stdin := strings.NewReader("read\nhello\nread\nworld\nexit\nread\n")
+	root := &cli.Command{Name: "app", Stdin: stdin, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+	root.AddCommand(&cli.Command{Name: "read", Stdin: stdin, Run: func(_ context.Context, cmd *cli.Command, _ []string) {
+		b := make([]byte, 6)
+		if _, err := io.ReadFull(cmd.Stdin, b); err != nil {
+			t.Error(err)
+		}
+		got = append(got, string(b))
+	}})
+	if err := (&cli.Shell{Root: root}).Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// commands can read input following their line.
+	if diff := cmp.Diff(got, []string{"hello\n", "world\n"}); diff != "" {
+		t.Errorf("(-got +want)%s", diff)
+	}
+}
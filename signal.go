@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const defaultGracePeriod = 5 * time.Second
+
+// signalHandler cancels Run context on first SIGINT or SIGTERM and forces exit on second one
+// or when grace period is over.
+type signalHandler struct {
+	exitFn   func(int)
+	grace    time.Duration
+	sigCh    chan os.Signal
+	done     chan struct{}
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+	received os.Signal
+	// deadline is the time of forced exit, which is grace period after first signal.
+	deadline time.Time
+	exitOnce sync.Once
+}
+
+// notifySignals return context canceled by signals. if HandleSignals is false, ctx and nil handler are returned.
+func (c *Command) notifySignals(ctx context.Context) (context.Context, *signalHandler) {
+	if !c.HandleSignals {
+		return ctx, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	h := &signalHandler{
+		exitFn: c.Exit,
+		grace:  c.GracePeriod,
+		sigCh:  make(chan os.Signal, 2),
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	if h.grace <= 0 {
+		h.grace = defaultGracePeriod
+	}
+	signal.Notify(h.sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go h.loop()
+	return ctx, h
+}
+
+func (h *signalHandler) loop() {
+	select {
+	case sig := <-h.sigCh:
+		h.mu.Lock()
+		h.received = sig
+		h.deadline = time.Now().Add(h.grace)
+		h.mu.Unlock()
+		h.cancel()
+	case <-h.done:
+		return
+	}
+	timer := time.NewTimer(h.grace)
+	defer timer.Stop()
+	select {
+	case <-h.sigCh:
+		h.exit()
+	case <-timer.C:
+		h.exit()
+	case <-h.done:
+	}
+}
+
+// signal return the first received signal.
+func (h *signalHandler) signal() (os.Signal, bool) {
+	if h == nil {
+		return nil, false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.received, h.received != nil
+}
+
+// postRunContext return context for PostRun. after signal, it is a new context which expires at forced exit.
+func (h *signalHandler) postRunContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := h.signal(); !ok {
+		return ctx, func() {}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return context.WithDeadline(context.Background(), h.deadline)
+}
+
+// exit call Exit with 128 + signal number like shell, only once.
+func (h *signalHandler) exit() {
+	sig, ok := h.signal()
+	if !ok {
+		return
+	}
+	h.exitOnce.Do(func() {
+		code := 1
+		if s, ok := sig.(syscall.Signal); ok {
+			code = 128 + int(s)
+		}
+		h.exitFn(code)
+	})
+}
+
+func (h *signalHandler) stop() {
+	if h == nil {
+		return
+	}
+	signal.Stop(h.sigCh)
+	close(h.done)
+	h.cancel()
+}
@@ -0,0 +1,154 @@
+//go:build !windows
+// +build !windows
+
+package cli_test
+
+import (
+	"context"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli"
+)
+
+func TestCommand_HandleSignals(t *testing.T) {
+	kill := func(t *testing.T, sig syscall.Signal) {
+		t.Helper()
+		if err := syscall.Kill(syscall.Getpid(), sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	type recorder struct {
+		mu     sync.Mutex
+		codes  []int
+		events []string
+		exited chan struct{}
+	}
+	build := func(rec *recorder, grace time.Duration, run func(context.Context)) *cli.Command {
+		root := &cli.Command{
+			Name:          "app",
+			HandleSignals: true,
+			GracePeriod:   grace,
+			Exit: func(code int) {
+				rec.mu.Lock()
+				defer rec.mu.Unlock()
+				rec.codes = append(rec.codes, code)
+				close(rec.exited)
+			},
+			PostRun: func(ctx context.Context, _ *cli.Command) {
+				rec.mu.Lock()
+				defer rec.mu.Unlock()
+				rec.events = append(rec.events, "post run ctx.Err()="+errString(ctx.Err()))
+			},
+		}
+		sub := &cli.Command{Name: "sub", Run: func(ctx context.Context, _ *cli.Command, _ []string) { run(ctx) }}
+		return root.AddCommand(sub)
+	}
+	newRecorder := func() *recorder { return &recorder{exited: make(chan struct{})} }
+	wait := func(t *testing.T, ch <-chan struct{}) {
+		t.Helper()
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+
+	t.Run("no signal", func(t *testing.T) {
+		rec := newRecorder()
+		build(rec, 0, func(context.Context) {}).ExecuteWithArgs(context.Background(), []string{"sub"})
+		if len(rec.codes) != 0 {
+			t.Errorf("Exit should not be called, got %v", rec.codes)
+		}
+		if diff := cmp.Diff(rec.events, []string{"post run ctx.Err()=<nil>"}); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+	})
+
+	tests := map[string]struct {
+		sig  syscall.Signal
+		want int
+	}{
+		"interrupt": {sig: syscall.SIGINT, want: 130},
+		"terminate": {sig: syscall.SIGTERM, want: 143},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := newRecorder()
+			build(rec, time.Second, func(ctx context.Context) {
+				kill(t, tc.sig)
+				wait(t, ctx.Done())
+			}).ExecuteWithArgs(context.Background(), []string{"sub"})
+			if diff := cmp.Diff(rec.codes, []int{tc.want}); diff != "" {
+				t.Errorf("exit code (-got +want)%s", diff)
+			}
+			if diff := cmp.Diff(rec.events, []string{"post run ctx.Err()=<nil>"}); diff != "" {
+				t.Errorf("post run should be called with live context (-got +want)%s", diff)
+			}
+		})
+	}
+
+	t.Run("second signal forces exit", func(t *testing.T) {
+		rec := newRecorder()
+		build(rec, time.Minute, func(ctx context.Context) {
+			kill(t, syscall.SIGINT)
+			wait(t, ctx.Done())
+			kill(t, syscall.SIGINT)
+			wait(t, rec.exited)
+		}).ExecuteWithArgs(context.Background(), []string{"sub"})
+		if diff := cmp.Diff(rec.codes, []int{130}); diff != "" {
+			t.Errorf("exit code (-got +want)%s", diff)
+		}
+	})
+
+	t.Run("grace period", func(t *testing.T) {
+		rec := newRecorder()
+		build(rec, 50*time.Millisecond, func(ctx context.Context) {
+			kill(t, syscall.SIGTERM)
+			// ignore cancellation until forced exit.
+			wait(t, rec.exited)
+		}).ExecuteWithArgs(context.Background(), []string{"sub"})
+		if diff := cmp.Diff(rec.codes, []int{143}); diff != "" {
+			t.Errorf("exit code (-got +want)%s", diff)
+		}
+	})
+}
+
+func TestCommand_HandleSignals_postRunDeadline(t *testing.T) {
+	const grace = time.Second
+	var killed time.Time
+	var deadline time.Time
+	root := &cli.Command{
+		Name:          "app",
+		HandleSignals: true,
+		GracePeriod:   grace,
+		Exit:          func(int) {},
+		Run: func(ctx context.Context, _ *cli.Command, _ []string) {
+			killed = time.Now()
+			if err := syscall.Kill(syscall.Getpid(), syscall.SIGINT); err != nil {
+				t.Fatal(err)
+			}
+			<-ctx.Done()
+			time.Sleep(grace / 2)
+		},
+		PostRun: func(ctx context.Context, _ *cli.Command) {
+			deadline, _ = ctx.Deadline()
+		},
+	}
+	root.ExecuteWithArgs(context.Background(), nil)
+	// PostRun shares the deadline of forced exit which starts at the signal.
+	if d := deadline.Sub(killed); d < grace || d > grace+grace/4 {
+		t.Errorf("deadline should be grace period after signal, got %v", d)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return err.Error()
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ymgyt/cli/parser"
+)
+
+var (
+	ErrAliasCycle    = errors.New("alias cycle detected")
+	ErrAliasShadowed = errors.New("alias is shadowed by sub command")
+)
+
+// AliasError describes the user alias which could not be expanded.
+type AliasError struct {
+	Alias string
+	Err   error
+}
+
+func (e *AliasError) Error() string {
+	return fmt.Sprintf("alias %s: %s", e.Alias, e.Err)
+}
+
+func (e *AliasError) Unwrap() error { return e.Err }
+
+// expandUserAliases replace the first argument with its expansion while it is a user alias.
+func (c *Command) expandUserAliases(args []string) ([]string, error) {
+	var stack []string
+	for len(args) > 0 {
+		name := args[0]
+		expansion, ok := c.userAlias(name)
+		if !ok {
+			break
+		}
+		for _, expanded := range stack {
+			if expanded == name {
+				return nil, &AliasError{Alias: stack[0], Err: fmt.Errorf("%w (%s)", ErrAliasCycle, strings.Join(append(stack, name), " -> "))}
+			}
+		}
+		stack = append(stack, name)
+		words, err := parser.SplitWords(expansion)
+		if err != nil {
+			return nil, &AliasError{Alias: name, Err: err}
+		}
+		args = append(words, args[1:]...)
+	}
+	return args, nil
+}
+
+// userAlias return expansion of the alias. aliases shadowed by sub commands are ignored.
+func (c *Command) userAlias(name string) (string, bool) {
+	expansion, ok := c.UserAliases[name]
+	if !ok || c.Lookup(name) != nil {
+		return "", false
+	}
+	return expansion, true
+}
+
+// userAliasNames return sorted names of user aliases which are not shadowed.
+func (c *Command) userAliasNames() []string {
+	var names []string
+	for name := range c.UserAliases {
+		if _, ok := c.userAlias(name); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,111 @@
+package cli_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli"
+)
+
+func TestCommand_UserAliases(t *testing.T) {
+	build := func() (*cli.Command, *[]string, *string) {
+		var env string
+		var got []string
+		root := &cli.Command{Name: "app", UserAliases: map[string]string{
+			"deploy-prod": `deploy --env=prod "release note"`,
+			"dp":          "deploy-prod --dry-run",
+			"ship":        "dp",
+			"loop":        "loop2 x",
+			"loop2":       "loop",
+			"deploy":      "echo shadowed",
+			"d":           "echo shadowed",
+			"broken":      `deploy "unterminated`,
+		}}
+		deploy := &cli.Command{
+			Name:      "deploy",
+			Aliases:   []string{"d"},
+			ShortDesc: "deploy app",
+			Run:       func(_ context.Context, _ *cli.Command, args []string) { got = args },
+		}
+		var dryRun bool
+		deploy.Options().
+			Add(&cli.StringOpt{Var: &env, Long: "env"}).
+			Add(&cli.BoolOpt{Var: &dryRun, Long: "dry-run"})
+		return root.AddCommand(deploy), &got, &env
+	}
+
+	t.Run("nested", func(t *testing.T) {
+		root, got, env := build()
+		root.ExecuteWithArgs(context.Background(), []string{"ship", "extra"})
+		if *env != "prod" {
+			t.Errorf("env got %q", *env)
+		}
+		if diff := cmp.Diff(*got, []string{"release note", "extra"}); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+	})
+
+	t.Run("never shadow", func(t *testing.T) {
+		root, got, _ := build()
+		root.ExecuteWithArgs(context.Background(), []string{"d", "x"})
+		if diff := cmp.Diff(*got, []string{"x"}); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		root, _, _ := build()
+		_, err := root.Parse([]string{"loop"})
+		if !errors.Is(err, cli.ErrAliasCycle) || !strings.Contains(err.Error(), "loop -> loop2 -> loop") {
+			t.Errorf("want cycle error, got %v", err)
+		}
+		var aliasErr *cli.AliasError
+		if _, err := root.Parse([]string{"broken"}); !errors.As(err, &aliasErr) || aliasErr.Alias != "broken" {
+			t.Errorf("want AliasError, got %v", err)
+		}
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		root, _, _ := build()
+		var verr *cli.ValidationError
+		if !errors.As(root.Validate(), &verr) {
+			t.Fatal("want ValidationError")
+		}
+		var got []string
+		for _, p := range verr.Problems {
+			got = append(got, p.Name)
+		}
+		if diff := cmp.Diff(got, []string{"broken", "d", "deploy", "loop", "loop2"}); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+	})
+
+	t.Run("help and completion", func(t *testing.T) {
+		root, _, _ := build()
+		root.UserAliases = map[string]string{"dp": "deploy --env=prod", "deploy": "shadowed"}
+		var b strings.Builder
+		cli.HelpFunc(&b, root)
+		want := `Usage:
+  app <command>
+
+SubCommands
+  deploy   deploy app
+
+Aliases
+  dp       deploy --env=prod
+`
+		if diff := cmp.Diff(b.String(), want); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+		if diff := cmp.Diff(root.Complete([]string{"d"}), []string{"deploy", "dp"}); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+		if diff := cmp.Diff(root.Complete([]string{"dp", "--d"}), []string{"--dry-run"}); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ymgyt/cli/flags"
+)
+
+var (
+	ErrDuplicateCommand = errors.New("command name or alias conflicts with sibling")
+	ErrShadowFlag       = errors.New("flag shadows persistent flag of parent")
+	ErrRunRequired      = errors.New("leaf command requires Run")
+)
+
+// Problem is a misconfiguration of the command tree.
+type Problem struct {
+	// Path is a path of the command which has problem.
+	Path string
+	// Name is a name of command or flag which has problem.
+	Name string
+	Err  error
+}
+
+func (p *Problem) Error() string {
+	if p.Name == "" {
+		return fmt.Sprintf("%s: %s", p.Path, p.Err)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Path, p.Name, p.Err)
+}
+
+func (p *Problem) Unwrap() error { return p.Err }
+
+// ValidationError contains all problems found by Validate.
+type ValidationError struct {
+	Problems []*Problem
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msgs = append(msgs, p.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether any problem matches target.
+func (e *ValidationError) Is(target error) bool {
+	for _, p := range e.Problems {
+		if errors.Is(p, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate walks the command tree and reports all misconfigurations at once.
+// it checks user aliases, errors of OptionConfigurator.Add, name and alias conflicts among sibling commands, invalid short flag names,
+// flags shadowing persistent flags of ancestors, flags which are bool in a command and non-bool in another,
+// and leaf commands without Run.
+func (c *Command) Validate() error {
+	v := &validator{boolFlags: make(map[string]bool), flagOwners: make(map[string]string)}
+	c.walk(func(cmd *Command) { cmd.lasyInit() })
+	v.validateUserAliases(c)
+	c.walk(v.validate)
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+type validator struct {
+	problems []*Problem
+	// boolFlags records whether flag name is bool or not. flagOwners records the path which defines it first.
+	boolFlags  map[string]bool
+	flagOwners map[string]string
+}
+
+func (v *validator) add(path, name string, err error) {
+	v.problems = append(v.problems, &Problem{Path: path, Name: name, Err: err})
+}
+
+func (v *validator) validateUserAliases(c *Command) {
+	names := make([]string, 0, len(c.UserAliases))
+	for name := range c.UserAliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c.Lookup(name) != nil {
+			v.add(c.Path(), name, ErrAliasShadowed)
+			continue
+		}
+		if _, err := c.expandUserAliases([]string{name}); err != nil {
+			v.add(c.Path(), name, errors.Unwrap(err))
+		}
+	}
+}
+
+func (v *validator) validate(c *Command) {
+	path := c.Path()
+
+	for _, oe := range c.optionErrs {
+		v.add(path, oe.Flag, oe.Err)
+	}
+
+	seen := make(map[string]bool)
+	for _, sub := range c.SubCommands {
+		for _, name := range append([]string{sub.Name}, sub.Aliases...) {
+			if seen[name] {
+				v.add(path, name, ErrDuplicateCommand)
+			}
+			seen[name] = true
+		}
+	}
+
+	if len(c.SubCommands) == 0 && c.runDefaulted {
+		v.add(path, "", ErrRunRequired)
+	}
+
+	check := func(f *flags.Flag) {
+		name := flagDisplayName(f.Name())
+		if err := f.Validate(); err != nil {
+			v.add(path, name, err)
+		}
+		for p := c.parent; p != nil; p = p.parent {
+			for _, n := range flagNamesOf(f) {
+				if pf, err := p.persistentFlagSet.Lookup(n); err == nil && pf != f {
+					v.add(path, name, fmt.Errorf("%w (%s)", ErrShadowFlag, p.Path()))
+					break
+				}
+			}
+		}
+		for _, n := range flagNamesOf(f) {
+			isBool, ok := v.boolFlags[n]
+			if !ok {
+				v.boolFlags[n] = f.IsBool()
+				v.flagOwners[n] = path
+				continue
+			}
+			if isBool != f.IsBool() {
+				v.add(path, flagDisplayName(n), fmt.Errorf("%w (%s)", flags.ErrBoolAndNonBoolFlagNotCompatible, v.flagOwners[n]))
+			}
+		}
+	}
+	c.flagSet.Traverse(check)
+	c.persistentFlagSet.Traverse(check)
+}
+
+func flagNamesOf(f *flags.Flag) []string {
+	var names []string
+	for _, name := range append([]string{f.Long, f.Short}, f.Aliases...) {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	aliases := make([]string, 0, len(f.DeprecatedAliases))
+	for alias := range f.DeprecatedAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return append(names, aliases...)
+}
@@ -0,0 +1,78 @@
+package cli_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ymgyt/cli"
+	"github.com/ymgyt/cli/flags"
+)
+
+func TestCommand_Validate(t *testing.T) {
+	run := func(_ context.Context, _ *cli.Command, _ []string) {}
+
+	t.Run("valid", func(t *testing.T) {
+		var verbose bool
+		var level int
+		root := &cli.Command{Name: "app"}
+		root.PersistentOptions().Add(&cli.BoolOpt{Var: &verbose, Long: "verbose", Short: "v"})
+		sub := &cli.Command{Name: "sub", Aliases: []string{"s"}, Run: run}
+		sub.Options().Add(&cli.IntOpt{Var: &level, Long: "level", Short: "l"})
+		root.AddCommand(sub)
+
+		if err := root.Validate(); err != nil {
+			t.Errorf("Validate() %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var verbose, b bool
+		var s, long string
+		root := &cli.Command{Name: "app"}
+		root.PersistentOptions().Add(&cli.BoolOpt{Var: &verbose, Long: "verbose", Short: "v"})
+		a := &cli.Command{Name: "a", Aliases: []string{"x"}, Run: run}
+		a.Options().
+			Add(&cli.BoolOpt{Var: &b, Long: "verbose", Short: "v"}).
+			Add(&cli.BoolOpt{Var: &b, Long: "force"}).
+			Add(&cli.StringOpt{Var: &long, Long: "name", Short: "nm"}).
+			Add(&cli.BoolOpt{Var: &b, Long: "quiet", DeprecatedAliases: map[string]string{"num": "use --quiet", "cnt": "use --quiet", "limit": "use --quiet"}})
+		x := &cli.Command{Name: "x"}
+		x.Options().
+			Add(&cli.StringOpt{Var: &s, Long: "force"}).
+			Add(&cli.StringOpt{Var: &s, Long: "num"}).
+			Add(&cli.StringOpt{Var: &s, Long: "limit"}).
+			Add(&cli.StringOpt{Var: &s, Long: "cnt"})
+		root.AddCommand(x).AddCommand(a)
+
+		err := root.Validate()
+		var verr *cli.ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("want ValidationError, got %v", err)
+		}
+		var got []string
+		for _, p := range verr.Problems {
+			got = append(got, p.Error())
+		}
+		want := []string{
+			"app: x: command name or alias conflicts with sibling",
+			"app x: leaf command requires Run",
+			"app a: --verbose: flag shadows persistent flag of parent (app)",
+			"app a: --force: bool and non-bool flags are not compatible (app x)",
+			"app a: --name: invalid short flag name",
+			"app a: --cnt: bool and non-bool flags are not compatible (app x)",
+			"app a: --limit: bool and non-bool flags are not compatible (app x)",
+			"app a: --num: bool and non-bool flags are not compatible (app x)",
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("(-got +want)%s", diff)
+		}
+		for _, target := range []error{cli.ErrDuplicateCommand, cli.ErrShadowFlag, cli.ErrRunRequired, flags.ErrInvalidShortFlag, flags.ErrBoolAndNonBoolFlagNotCompatible} {
+			if !errors.Is(err, target) {
+				t.Errorf("errors.Is(err, %v) = false", target)
+			}
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// BuildVersion, BuildRevision and BuildTime are used by version output if they are set.
+// they are intended to be injected by ldflags like
+//
+//	go build -ldflags "-X github.com/ymgyt/cli.BuildVersion=$(cat VERSION) -X github.com/ymgyt/cli.BuildRevision=$(git rev-parse HEAD)"
+//
+// nolint: gochecknoglobals
+var (
+	BuildVersion  string
+	BuildRevision string
+	BuildTime     string
+)
+
+// VersionInfo is printed by "--version" flag and "version" sub command.
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Dirty     bool   `json:"dirty,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Module    string `json:"module,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// ReadVersionInfo return version from BuildVersion, BuildRevision and BuildTime,
+// then fill missing values from build info embedded by go command.
+// vcs revision, dirty flag and go version of build info are available when built with go1.18 or later.
+func ReadVersionInfo() *VersionInfo {
+	info := &VersionInfo{
+		Version:   BuildVersion,
+		Revision:  BuildRevision,
+		Time:      BuildTime,
+		GoVersion: runtime.Version(),
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if ok {
+		info.Module = bi.Main.Path
+		if info.Version == "" {
+			info.Version = bi.Main.Version
+		}
+		readBuildSettings(info, bi)
+	}
+	if info.Version == "" {
+		info.Version = "(devel)"
+	}
+	return info
+}
+
+// VersionInfo return version of the command tree. Version of root command precedes ReadVersionInfo.
+func (c *Command) VersionInfo() *VersionInfo {
+	root := c.root()
+	info := ReadVersionInfo()
+	if root.Version != "" {
+		info.Version = root.Version
+	}
+	return info
+}
+
+// writeVersion write version in format "text" or "json".
+func (c *Command) writeVersion(w io.Writer, format string) error {
+	info := c.VersionInfo()
+	switch format {
+	case "", "text":
+		fmt.Fprintf(w, "%s version %s\n", c.root().Name, info.Version)
+		if info.Revision != "" {
+			dirty := ""
+			if info.Dirty {
+				dirty = " (dirty)"
+			}
+			fmt.Fprintf(w, "  revision: %s%s\n", info.Revision, dirty)
+		}
+		if info.Time != "" {
+			fmt.Fprintf(w, "  time:     %s\n", info.Time)
+		}
+		fmt.Fprintf(w, "  go:       %s\n", info.GoVersion)
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	default:
+		return fmt.Errorf("unknown output format %q, must be text or json", format)
+	}
+}
+
+// addVersion add persistent "--version" flag and "version" sub command if they are enabled.
+// the sub command is marked as builtin so that AddCommand replaces it with user's one.
+// it is called in lasyInit, so it must not call methods which call lasyInit.
+func (c *Command) addVersion() {
+	if c.VersionFlag {
+		c.PersistentOptions().Add(&BoolOpt{Var: &c.showVersion, Long: "version", Description: "print version"})
+	}
+	if !c.VersionCommand {
+		return
+	}
+	for _, sub := range c.SubCommands {
+		if sub.Name == "version" {
+			return
+		}
+	}
+	var output string
+	sub := &Command{
+		Name:      "version",
+		ShortDesc: "print version",
+		builtin:   true,
+		Stdin:     c.Stdin,
+		Stdout:    c.Stdout,
+		Stderr:    c.Stderr,
+		Exit:      c.Exit,
+		Run: func(_ context.Context, cmd *Command, _ []string) {
+			if err := cmd.writeVersion(cmd.Stdout, strings.ToLower(output)); err != nil {
+				fmt.Fprintf(cmd.Stderr, "error: %s\n", err)
+				cmd.Exit(1)
+			}
+		},
+	}
+	sub.Options().Add(&StringOpt{Var: &output, Long: "output", Short: "o", Default: "text", Description: "output format, text or json"})
+	sub.parent = c
+	c.SubCommands = append(c.SubCommands, sub)
+}
@@ -0,0 +1,140 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/ymgyt/cli"
+)
+
+func TestCommand_version(t *testing.T) {
+	defer func(v, r, tm string) { cli.BuildVersion, cli.BuildRevision, cli.BuildTime = v, r, tm }(
+		cli.BuildVersion, cli.BuildRevision, cli.BuildTime)
+	cli.BuildVersion, cli.BuildRevision, cli.BuildTime = "v0.0.1", "0123abc", "2026-10-18T00:00:00Z"
+
+	build := func(version string, stdout, stderr *bytes.Buffer) *cli.Command {
+		root := &cli.Command{
+			Name:           "app",
+			Version:        version,
+			VersionFlag:    true,
+			VersionCommand: true,
+			Stdout:         stdout,
+			Stderr:         stderr,
+			Exit:           func(code int) { stderr.WriteString("exit ") },
+		}
+		return root.AddCommand(&cli.Command{Name: "sub", Run: func(_ context.Context, _ *cli.Command, _ []string) {
+			stdout.WriteString("sub called")
+		}})
+	}
+	text := func(version string) string {
+		return "app version " + version + "\n" +
+			"  revision: 0123abc\n" +
+			"  time:     2026-10-18T00:00:00Z\n" +
+			"  go:       " + runtime.Version() + "\n"
+	}
+
+	tests := map[string]struct {
+		version    string
+		args       []string
+		wantStdout string
+		wantStderr bool
+	}{
+		"flag":                  {args: []string{"--version"}, wantStdout: text("v0.0.1")},
+		"flag of sub command":   {args: []string{"sub", "--version"}, wantStdout: text("v0.0.1")},
+		"configured version":    {version: "v1.2.3", args: []string{"--version"}, wantStdout: text("v1.2.3")},
+		"flag precedes run":     {args: []string{"--version", "sub"}, wantStdout: text("v0.0.1")},
+		"command":               {args: []string{"version"}, wantStdout: text("v0.0.1")},
+		"command text":          {args: []string{"version", "-o", "TEXT"}, wantStdout: text("v0.0.1")},
+		"unknown output format": {args: []string{"version", "-o", "yaml"}, wantStderr: true},
+		"no flag":               {args: []string{"sub"}, wantStdout: "sub called"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			build(tc.version, &stdout, &stderr).ExecuteWithArgs(context.Background(), tc.args)
+			if diff := cmp.Diff(stdout.String(), tc.wantStdout); diff != "" {
+				t.Errorf("(-got +want)\n%s", diff)
+			}
+			if got := stderr.Len() > 0; got != tc.wantStderr {
+				t.Errorf("stderr %q", stderr.String())
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		build("v1.2.3", &stdout, &stderr).ExecuteWithArgs(context.Background(), []string{"version", "--output=json"})
+		var got cli.VersionInfo
+		if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+			t.Fatalf("%s: %v", stdout.String(), err)
+		}
+		want := cli.VersionInfo{Version: "v1.2.3", Revision: "0123abc", Time: "2026-10-18T00:00:00Z", GoVersion: runtime.Version()}
+		if diff := cmp.Diff(got, want, cmpopts.IgnoreFields(cli.VersionInfo{}, "Module")); diff != "" {
+			t.Errorf("(-got +want)\n%s", diff)
+		}
+	})
+
+	t.Run("own version command", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		root := &cli.Command{Name: "app", VersionCommand: true, SubCommands: []*cli.Command{{Name: "version", Run: func(_ context.Context, _ *cli.Command, _ []string) {
+			stdout.WriteString("own")
+		}}}, Stdout: &stdout, Stderr: &stderr}
+		root.ExecuteWithArgs(context.Background(), []string{"version"})
+		if stdout.String() != "own" {
+			t.Errorf("got %q", stdout.String())
+		}
+	})
+
+	t.Run("replaced by AddCommand", func(t *testing.T) {
+		var stdout bytes.Buffer
+		root := &cli.Command{Name: "app", VersionCommand: true, Stdout: &stdout, Stderr: &stdout}
+		root.AddCommand(&cli.Command{Name: "version", Run: func(_ context.Context, _ *cli.Command, _ []string) {
+			stdout.WriteString("own")
+		}})
+		root.ExecuteWithArgs(context.Background(), []string{"version"})
+		if stdout.String() != "own" || len(root.SubCommands) != 1 {
+			t.Errorf("got %q, %d sub commands", stdout.String(), len(root.SubCommands))
+		}
+		defer func() {
+			if recover() == nil {
+				t.Error("adding user's command twice should panic")
+			}
+		}()
+		root.AddCommand(&cli.Command{Name: "version"})
+	})
+
+	t.Run("enabled after init", func(t *testing.T) {
+		root := (&cli.Command{Name: "app"}).AddCommand(&cli.Command{Name: "sub"})
+		root.VersionCommand = true
+		if root.Lookup("version") != nil {
+			t.Error("version command should not be added after init")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		root := &cli.Command{Name: "app", Version: "v1.2.3", Stdout: &stdout, Stderr: &stderr}
+		root.ExecuteWithArgs(context.Background(), []string{"--version"})
+		if strings.Contains(stdout.String(), "v1.2.3") || stderr.Len() == 0 {
+			t.Errorf("stdout %q, stderr %q", stdout.String(), stderr.String())
+		}
+	})
+}
+
+func TestReadVersionInfo(t *testing.T) {
+	defer func(v, r string) { cli.BuildVersion, cli.BuildRevision = v, r }(cli.BuildVersion, cli.BuildRevision)
+	cli.BuildVersion, cli.BuildRevision = "", ""
+
+	info := cli.ReadVersionInfo()
+	if info.Version == "" || info.GoVersion != runtime.Version() {
+		t.Errorf("build info fallback, got %+v", info)
+	}
+}